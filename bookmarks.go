@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// renderBookmarks writes a NETSCAPE-Bookmark-file-1 document that browsers
+// import as a "GitHub stars" folder holding one folder per section: the
+// --group-by sections, or the language sections when there are none.
+func renderBookmarks(w io.Writer, data templateData) error {
+	sections := data.Sections
+	if len(sections) == 0 {
+		sections = newSections(data.LangRepoMap, "alphabetical", nil)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+`)
+	fmt.Fprintf(&sb, "    <DT><H3>%s</H3>\n    <DL><p>\n", escapeHTML(feedTitle(data.UserName)))
+	for _, section := range sections {
+		fmt.Fprintf(&sb, "        <DT><H3>%s</H3>\n        <DL><p>\n", escapeHTML(section.Name))
+		for _, repo := range section.Repos {
+			sb.WriteString("            <DT><A HREF=\"" + escapeHTML(repo.URL) + "\"")
+			if !repo.StarredAt.IsZero() {
+				fmt.Fprintf(&sb, " ADD_DATE=\"%d\"", repo.StarredAt.Unix())
+			}
+			sb.WriteString(">" + escapeHTML(repo.FullName) + "</A>\n")
+			if repo.Description != "" {
+				sb.WriteString("            <DD>" + escapeHTML(strings.Join(strings.Fields(repo.Description), " ")) + "\n")
+			}
+		}
+		sb.WriteString("        </DL><p>\n")
+	}
+	sb.WriteString("    </DL><p>\n</DL><p>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderBookmarks(t *testing.T) {
+	langRepoMap, repositories := groupByLanguage([]Repository{
+		{FullName: "a/go", URL: "https://github.com/a/go", Language: "Go", Description: "Tom & Jerry\nsecond line",
+			StarredAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+		{FullName: "x/y", URL: "https://github.com/x/y?a=1&b=2"},
+	})
+
+	var sb strings.Builder
+	if err := renderBookmarks(&sb, templateData{UserName: "juev", LangRepoMap: langRepoMap, Repositories: repositories}); err != nil {
+		t.Fatal(err)
+	}
+	want := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3>GitHub stars of juev</H3>
+    <DL><p>
+        <DT><H3>Go</H3>
+        <DL><p>
+            <DT><A HREF="https://github.com/a/go" ADD_DATE="1772445600">a/go</A>
+            <DD>Tom &amp; Jerry second line
+        </DL><p>
+        <DT><H3>Others</H3>
+        <DL><p>
+            <DT><A HREF="https://github.com/x/y?a=1&amp;b=2">x/y</A>
+        </DL><p>
+    </DL><p>
+</DL><p>
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("bookmarks =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderBookmarksUsesGroupSections(t *testing.T) {
+	var sb strings.Builder
+	err := renderBookmarks(&sb, templateData{UserName: "juev", Sections: []Section{
+		{Name: "kubernetes", Repos: []Repository{{FullName: "a/k8s", URL: "https://github.com/a/k8s"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "<DT><H3>kubernetes</H3>") {
+		t.Errorf("output missing group folder:\n%s", sb.String())
+	}
+}
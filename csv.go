@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns maps --columns names to the value of that column for a
+// repository. Topics are joined with ";" and timestamps use RFC 3339.
+var csvColumns = map[string]func(Repository) string{
+	"full_name":   func(r Repository) string { return r.FullName },
+	"url":         func(r Repository) string { return r.URL },
+	"language":    func(r Repository) string { return r.Language },
+	"description": func(r Repository) string { return r.Description },
+	"homepage":    func(r Repository) string { return r.Homepage },
+	"stars":       func(r Repository) string { return strconv.Itoa(r.Stars) },
+	"forks":       func(r Repository) string { return strconv.Itoa(r.Forks) },
+	"topics":      func(r Repository) string { return strings.Join(r.Topics, ";") },
+	"license":     func(r Repository) string { return r.License },
+	"archived":    func(r Repository) string { return strconv.FormatBool(r.Archived) },
+	"fork":        func(r Repository) string { return strconv.FormatBool(r.Fork) },
+	"owner":       func(r Repository) string { return r.Owner.Login },
+	"starred_at":  func(r Repository) string { return formatDate(time.RFC3339, r.StarredAt) },
+	"pushed_at":   func(r Repository) string { return formatDate(time.RFC3339, r.PushedAt) },
+}
+
+// defaultCSVColumns are the columns written when --columns is not set.
+var defaultCSVColumns = []string{"full_name", "url", "language", "description", "stars", "topics", "starred_at"}
+
+// renderCSV writes one RFC 4180 record per repository, preceded by a header
+// row naming the columns.
+func renderCSV(w io.Writer, data templateData) error {
+	return renderDelimited(w, data, ',', true)
+}
+
+// renderTSV writes the same rows as renderCSV separated by tabs.
+func renderTSV(w io.Writer, data templateData) error {
+	return renderDelimited(w, data, '\t', false)
+}
+
+func renderDelimited(w io.Writer, data templateData, comma rune, crlf bool) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.UseCRLF = crlf
+
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, repo := range data.Repositories {
+		for i, column := range columns {
+			record[i] = csvColumns[column](repo)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
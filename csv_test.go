@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderCSVQuotesDescriptions(t *testing.T) {
+	data := templateData{Repositories: []Repository{
+		{
+			FullName: "a/b", URL: "https://github.com/a/b", Language: "Go",
+			Description: "says \"hi\", then\nbreaks", Stars: 12, Topics: []string{"cli", "go"},
+			StarredAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{FullName: "x/y", URL: "https://github.com/x/y"},
+	}}
+
+	var sb strings.Builder
+	if err := renderCSV(&sb, data); err != nil {
+		t.Fatal(err)
+	}
+	want := "full_name,url,language,description,stars,topics,starred_at\r\n" +
+		"a/b,https://github.com/a/b,Go,\"says \"\"hi\"\", then\r\nbreaks\",12,cli;go,2026-03-02T10:00:00Z\r\n" +
+		"x/y,https://github.com/x/y,,,0,,\r\n"
+	if got := sb.String(); got != want {
+		t.Fatalf("csv =\n%q\nwant\n%q", got, want)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 || records[1][3] != "says \"hi\", then\nbreaks" {
+		t.Errorf("records = %q", records)
+	}
+}
+
+func TestRenderTSVWithCustomColumns(t *testing.T) {
+	oldColumns := columns
+	columns = []string{"full_name", "license", "archived", "owner"}
+	t.Cleanup(func() { columns = oldColumns })
+
+	var sb strings.Builder
+	err := renderTSV(&sb, templateData{Repositories: []Repository{
+		{FullName: "a/b", License: "MIT", Archived: true, Owner: Owner{Login: "a"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	if want := []string{"full_name\tlicense\tarchived\towner", "a/b\tMIT\ttrue\ta"}; !slices.Equal(lines, want) {
+		t.Fatalf("tsv lines = %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"html"
+	"strings"
+)
+
+// markdownEscaper backslash-escapes the characters that start inline Markdown
+// constructs (code spans, emphasis, links, raw HTML, tables, strikethrough).
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`>`, `\>`,
+	`|`, `\|`,
+	`~`, `\~`,
+)
+
+// escapeMarkdown makes s safe to render inline in a Markdown list item or
+// table cell: line breaks are folded into spaces, inline syntax is escaped
+// and a leading "#" is escaped so the text can never become a heading.
+func escapeMarkdown(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = markdownEscaper.Replace(s)
+	if strings.HasPrefix(s, "#") {
+		s = `\` + s
+	}
+	return s
+}
+
+// escapeHTML escapes s for use in HTML text or a quoted attribute value.
+func escapeHTML(s string) string {
+	return html.EscapeString(s)
+}
+
+// zeroWidthSpace is Org's escape character.
+const zeroWidthSpace = "\u200b"
+
+// orgEscaper breaks up link brackets with zero-width spaces.
+var orgEscaper = strings.NewReplacer(
+	"[", "["+zeroWidthSpace,
+	"]", zeroWidthSpace+"]",
+)
+
+// orgEmphasisMarkers are the Org emphasis and verbatim markers: bold,
+// italic, underline, verbatim, code and strike-through.
+const orgEmphasisMarkers = "*/_=~+"
+
+// orgEmphasisPre are the characters Org accepts before an opening emphasis
+// marker, besides whitespace and the start of the text.
+const orgEmphasisPre = `-({'"`
+
+// escapeOrg makes s safe to render inline in an Org list item: line breaks
+// are folded into spaces, link brackets are broken up so the text cannot
+// open or close links, and a zero-width space is put before every emphasis
+// marker that could open emphasis, so "*bold*" and "=code=" render literally
+// while markers inside words and URLs are left alone. A leading "#" is
+// escaped the same way so it cannot start a comment.
+func escapeOrg(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = orgEscaper.Replace(s)
+
+	var sb strings.Builder
+	prev := ' '
+	for i, r := range s {
+		opens := strings.ContainsRune(orgEmphasisMarkers, r) &&
+			(prev == ' ' || strings.ContainsRune(orgEmphasisPre, prev))
+		if opens || (i == 0 && r == '#') {
+			sb.WriteString(zeroWidthSpace)
+		}
+		sb.WriteRune(r)
+		prev = r
+	}
+	return sb.String()
+}
+
+// escapeAsciiDoc makes s safe to render inline in AsciiDoc: line breaks are
+// folded into spaces and the text is wrapped in a pass macro that only
+// escapes HTML special characters, so formatting marks, attribute references
+// and macros in s are rendered literally.
+func escapeAsciiDoc(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return "pass:c[" + strings.ReplaceAll(s, "]", `\]`) + "]"
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestEscapeMarkdown(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"plain text", "plain text"},
+		{"  padded\n\tlines  ", "padded lines"},
+		{"#1 tool", `\#1 tool`},
+		{"issue #1", "issue #1"},
+		{"a|b", `a\|b`},
+	}
+	for _, tc := range cases {
+		if got := escapeMarkdown(tc.in); got != tc.want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestEscapeOrg(t *testing.T) {
+	const zws = "\u200b"
+	cases := []struct{ in, want string }{
+		{"plain text", "plain text"},
+		{"  padded\n\tlines  ", "padded lines"},
+		{"*bold* /italic/ _under_", zws + "*bold* " + zws + "/italic/ " + zws + "_under_"},
+		{"=code= ~verbatim~ +strike+", zws + "=code= " + zws + "~verbatim~ " + zws + "+strike+"},
+		{"(*a*) '-b-' \"=c=\"", "(" + zws + "*a*) '-b-' \"" + zws + "=c=\""},
+		{"snake_case a+b https://x.org/a/b", "snake_case a+b https://x.org/a/b"},
+		{"[[x][y]]", "[" + zws + "[" + zws + "x" + zws + "][" + zws + "y" + zws + "]" + zws + "]"},
+		{"#1 tool", zws + "#1 tool"},
+		{"issue #1", "issue #1"},
+	}
+	for _, tc := range cases {
+		if got := escapeOrg(tc.in); got != tc.want {
+			t.Errorf("escapeOrg(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestEscapeHTML(t *testing.T) {
+	in := `<a href="x">Tom & 'Jerry'</a>`
+	want := `&lt;a href=&#34;x&#34;&gt;Tom &amp; &#39;Jerry&#39;&lt;/a&gt;`
+	if got := escapeHTML(in); got != want {
+		t.Fatalf("escapeHTML(%q) = %q, want %q", in, got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// feedTitle returns the title of the feeds of user's stars.
+func feedTitle(user string) string {
+	return fmt.Sprintf("GitHub stars of %s", user)
+}
+
+// feedLink returns the page listing user's stars on GitHub.
+func feedLink(user string) string {
+	return fmt.Sprintf("https://github.com/%s?tab=stars", user)
+}
+
+// recentlyStarred returns the feedSize most recently starred repositories.
+// Repositories without a starred_at timestamp cannot be dated and are left out.
+func recentlyStarred(repositories []Repository) []Repository {
+	recent := make([]Repository, 0, feedSize)
+	for _, repo := range byStarredAt(repositories) {
+		if len(recent) == feedSize || repo.StarredAt.IsZero() {
+			break
+		}
+		recent = append(recent, repo)
+	}
+	return recent
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Link    atomLink     `xml:"link"`
+	Summary *atomSummary `xml:"summary"`
+}
+
+type atomSummary struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// renderAtom writes an Atom 1.0 feed of the most recently starred
+// repositories. Entries are dated by starred_at and identified by the
+// repository URL, so feed readers see each star exactly once.
+func renderAtom(w io.Writer, data templateData) error {
+	recent := recentlyStarred(data.Repositories)
+	feed := atomFeed{
+		ID:     feedLink(data.UserName),
+		Title:  feedTitle(data.UserName),
+		Author: atomPerson{Name: data.UserName, URI: "https://github.com/" + data.UserName},
+		Links:  []atomLink{{Rel: "alternate", Href: feedLink(data.UserName)}},
+	}
+	// the feed changes only when something new is starred
+	updated := time.Unix(0, 0)
+	if len(recent) > 0 {
+		updated = recent[0].StarredAt
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	for _, repo := range recent {
+		entry := atomEntry{
+			ID:      repo.URL,
+			Title:   repo.FullName,
+			Updated: repo.StarredAt.UTC().Format(time.RFC3339),
+			Link:    atomLink{Rel: "alternate", Href: repo.URL},
+		}
+		if repo.Description != "" {
+			entry.Summary = &atomSummary{Type: "text", Text: repo.Description}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return writeXML(w, feed)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// renderRSS writes the same entries as renderAtom as an RSS 2.0 feed.
+func renderRSS(w io.Writer, data templateData) error {
+	recent := recentlyStarred(data.Repositories)
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       feedTitle(data.UserName),
+			Link:        feedLink(data.UserName),
+			Description: fmt.Sprintf("Repositories recently starred by %s", data.UserName),
+		},
+	}
+	if len(recent) > 0 {
+		feed.Channel.LastBuildDate = recent[0].StarredAt.UTC().Format(time.RFC1123Z)
+	}
+	for _, repo := range recent {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       repo.FullName,
+			Link:        repo.URL,
+			Description: repo.Description,
+			GUID:        rssGUID{IsPermaLink: true, Value: repo.URL},
+			PubDate:     repo.StarredAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return writeXML(w, feed)
+}
+
+// writeXML writes v as an indented XML document with a declaration.
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func feedFixture() templateData {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 8, 0, 0, 0, time.UTC) }
+	return templateData{UserName: "juev", Repositories: []Repository{
+		{FullName: "a/old", URL: "https://github.com/a/old", StarredAt: day(1)},
+		{FullName: "a/undated", URL: "https://github.com/a/undated"},
+		{FullName: "a/new", URL: "https://github.com/a/new", Description: "fast & <small>", StarredAt: day(9)},
+		{FullName: "a/mid", URL: "https://github.com/a/mid", StarredAt: day(5)},
+	}}
+}
+
+func TestRenderAtom(t *testing.T) {
+	oldFeedSize := feedSize
+	feedSize = 2
+	t.Cleanup(func() { feedSize = oldFeedSize })
+
+	var sb strings.Builder
+	if err := renderAtom(&sb, feedFixture()); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	var feed atomFeed
+	if err := xml.Unmarshal([]byte(out), &feed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if feed.XMLName.Space != "http://www.w3.org/2005/Atom" {
+		t.Errorf("namespace = %q, want Atom 1.0", feed.XMLName.Space)
+	}
+	if feed.ID == "" || feed.Title == "" || feed.Author.Name != "juev" {
+		t.Errorf("feed id/title/author = %q/%q/%q", feed.ID, feed.Title, feed.Author.Name)
+	}
+	if feed.Updated != "2026-10-09T08:00:00Z" {
+		t.Errorf("updated = %q, want newest starred_at", feed.Updated)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(feed.Entries))
+	}
+	newest := feed.Entries[0]
+	if newest.ID != "https://github.com/a/new" || newest.Title != "a/new" || newest.Updated != "2026-10-09T08:00:00Z" {
+		t.Errorf("first entry = %+v", newest)
+	}
+	if newest.Summary == nil || newest.Summary.Text != "fast & <small>" {
+		t.Errorf("summary = %+v", newest.Summary)
+	}
+	if feed.Entries[1].ID != "https://github.com/a/mid" || feed.Entries[1].Summary != nil {
+		t.Errorf("second entry = %+v", feed.Entries[1])
+	}
+	if !strings.HasPrefix(out, xml.Header) {
+		t.Error("feed must start with an XML declaration")
+	}
+}
+
+func TestRenderRSS(t *testing.T) {
+	var sb strings.Builder
+	if err := renderRSS(&sb, feedFixture()); err != nil {
+		t.Fatal(err)
+	}
+
+	var feed rssFeed
+	if err := xml.Unmarshal([]byte(sb.String()), &feed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, sb.String())
+	}
+	if feed.Version != "2.0" || len(feed.Channel.Items) != 3 {
+		t.Fatalf("version = %q, items = %d, want 2.0 and 3 dated items", feed.Version, len(feed.Channel.Items))
+	}
+	item := feed.Channel.Items[0]
+	if item.GUID.Value != "https://github.com/a/new" || !item.GUID.IsPermaLink || item.PubDate != "Fri, 09 Oct 2026 08:00:00 +0000" {
+		t.Errorf("first item = %+v", item)
+	}
+}
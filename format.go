@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// outputFormat renders the collected stars for one --format value.
+type outputFormat struct {
+	render func(w io.Writer, data templateData) error
+	// file is the file written to the repository with --repository.
+	file string
+}
+
+// outputFormats maps --format values to their renderer.
+var outputFormats = map[string]outputFormat{
+	"template":  {render: renderTemplate, file: "README.md"},
+	"json":      {render: renderJSON, file: "stars.json"},
+	"csv":       {render: renderCSV, file: "stars.csv"},
+	"tsv":       {render: renderTSV, file: "stars.tsv"},
+	"html":      {render: renderHTML, file: "index.html"},
+	"atom":      {render: renderAtom, file: "atom.xml"},
+	"rss":       {render: renderRSS, file: "rss.xml"},
+	"bookmarks": {render: renderBookmarks, file: "bookmarks.html"},
+	"opml":      {render: renderOPML, file: "stars.opml"},
+}
+
+// renderTemplate executes the output template (the embedded one unless
+// --template is set).
+func renderTemplate(w io.Writer, data templateData) error {
+	temp, err := parseTemplate(content)
+	if err != nil {
+		return fmt.Errorf("template parse failed: %w", err)
+	}
+	return temp.Execute(w, data)
+}
@@ -3,9 +3,13 @@ package main
 import (
 	"cmp"
 	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"net/http"
 	"slices"
 	"time"
@@ -32,6 +36,25 @@ type Repository struct {
 	URL         string
 	Language    string
 	Description string
+	StarredAt   time.Time
+	Homepage    string
+	Stars       int
+	Forks       int
+	Topics      []string
+	// License is the SPDX identifier, e.g. "MIT"; empty when GitHub detected
+	// no license or one it could not identify.
+	License  string
+	Archived bool
+	Fork     bool
+	PushedAt time.Time
+	Owner    Owner
+}
+
+// Owner struct for storing the user or organization owning a Repository
+type Owner struct {
+	Login     string
+	URL       string
+	AvatarURL string
 }
 
 // httpClientTimeout bounds a single API request so a stalled connection
@@ -57,9 +80,8 @@ func New(token string) (*GitHub, error) {
 
 // GetRepositories getting repositories from GitHub
 func (g *GitHub) GetRepositories(ctx context.Context) (map[string][]Repository, []Repository, error) {
-	repositories := make([]Repository, 0, repositoriesCount)
-	langRepoMap := make(map[string][]Repository, langReposCount)
-
+	// ListStarred requests the application/vnd.github.star+json media type, so
+	// every StarredRepository carries the starred_at timestamp.
 	opt := func(page int) *github.ActivityListStarredOptions {
 		return &github.ActivityListStarredOptions{
 			ListOptions: github.ListOptions{
@@ -102,43 +124,76 @@ func (g *GitHub) GetRepositories(ctx context.Context) (map[string][]Repository,
 		repos = append(repos, r...)
 	}
 
+	repositories := make([]Repository, 0, len(repos))
 	for _, r := range repos {
-		repo := Repository{
-			FullName:    r.Repository.GetFullName(),
-			URL:         r.Repository.GetHTMLURL(),
-			Language:    r.Repository.GetLanguage(),
-			Description: r.Repository.GetDescription(),
-		}
-		repositories = append(repositories, repo)
-		lang := repo.Language
-		if lang == "" {
-			lang = "Others"
-		}
-		if alias, ok := langAliases[lang]; ok {
-			lang = alias
-		}
-
-		if _, ok := langRepoMap[lang]; !ok {
-			langRepoMap[lang] = make([]Repository, 0, langReposCount)
-		}
-		langRepoMap[lang] = append(langRepoMap[lang], repo)
+		repositories = append(repositories, newRepository(r))
 	}
+	langRepoMap, repositories := groupByLanguage(repositories)
+	return langRepoMap, repositories, nil
+}
 
-	if len(repositories) == 0 {
-		return langRepoMap, repositories, nil
-	}
+// groupByLanguage buckets repositories by language, merging stale language
+// names and collecting repositories without one under "Others". Both the
+// returned map sections and the flat slice are sorted by FullName.
+func groupByLanguage(repositories []Repository) (map[string][]Repository, []Repository) {
+	langRepoMap := groupRepositories(repositories, func(repo Repository) []string {
+		return []string{languageSection(repo)}
+	})
 
 	slices.SortFunc(repositories, func(a, b Repository) int {
 		return cmp.Compare(a.FullName, b.FullName)
 	})
 
-	for _, repositories := range langRepoMap {
-		slices.SortFunc(repositories, func(a, b Repository) int {
-			return cmp.Compare(a.FullName, b.FullName)
-		})
+	return langRepoMap, repositories
+}
+
+// languageSection returns the name of the language section repo belongs to.
+func languageSection(repo Repository) string {
+	lang := repo.Language
+	if lang == "" {
+		lang = others
 	}
+	if alias, ok := langAliases[lang]; ok {
+		lang = alias
+	}
+	return lang
+}
 
-	return langRepoMap, repositories, nil
+// noAssertion is the SPDX id GitHub reports for a license it found but could
+// not identify.
+const noAssertion = "NOASSERTION"
+
+// spdxID returns id, or "" for a license GitHub could not identify.
+func spdxID(id string) string {
+	if id == noAssertion {
+		return ""
+	}
+	return id
+}
+
+// newRepository converts a starred repository returned by the API.
+func newRepository(r *github.StarredRepository) Repository {
+	gr := r.GetRepository()
+	return Repository{
+		FullName:    gr.GetFullName(),
+		URL:         gr.GetHTMLURL(),
+		Language:    gr.GetLanguage(),
+		Description: gr.GetDescription(),
+		StarredAt:   r.GetStarredAt().Time,
+		Homepage:    gr.GetHomepage(),
+		Stars:       gr.GetStargazersCount(),
+		Forks:       gr.GetForksCount(),
+		Topics:      gr.Topics,
+		License:     spdxID(gr.GetLicense().GetSPDXID()),
+		Archived:    gr.GetArchived(),
+		Fork:        gr.GetFork(),
+		PushedAt:    gr.GetPushedAt().Time,
+		Owner: Owner{
+			Login:     gr.GetOwner().GetLogin(),
+			URL:       gr.GetOwner().GetHTMLURL(),
+			AvatarURL: gr.GetOwner().GetAvatarURL(),
+		},
+	}
 }
 
 // langAliases merges stale language names that the GitHub API still returns
@@ -191,65 +246,260 @@ func sleepContext(ctx context.Context, d time.Duration) error {
 	}
 }
 
-// UpdateRequest describes the README.md update to perform.
+// UpdateRequest describes the files to commit.
 type UpdateRequest struct {
 	Owner   string
 	Repo    string
 	Message string
-	Content []byte
+	// Files are committed together; their paths are relative to the
+	// repository root.
+	Files []outputFile
+	// Branch is the branch to commit to; the default branch when empty.
+	Branch string
+	// Prune is a directory whose Markdown files not among Files are
+	// deleted, so --split pages of vanished sections go away.
+	Prune string
+	// Create describes the repository to create when it does not exist;
+	// when nil a missing repository is an error.
+	Create *NewRepository
 }
 
-// UpdateReadmeFile creates or updates README.md in the given repository. If
-// the file changed between reading and updating (409 Conflict), it re-reads
-// the SHA and retries the update once.
-func (g *GitHub) UpdateReadmeFile(ctx context.Context, req UpdateRequest) error {
-	if _, _, err := g.client.Repositories.Get(ctx, req.Owner, req.Repo); err != nil {
-		return fmt.Errorf("cannot check repository %s/%s exists: %w", req.Owner, req.Repo, err)
+// NewRepository describes a repository created for publishing.
+type NewRepository struct {
+	// Org is the organization owning the repository; the authenticated user
+	// when empty.
+	Org         string
+	Description string
+	Homepage    string
+	Private     bool
+	Topics      []string
+}
+
+// refUpdateAttempts bounds how often UpdateFiles rebuilds its commit when the
+// branch moves before the ref update.
+const refUpdateAttempts = 3
+
+// errBranchMoved reports a ref update that was not a fast-forward.
+var errBranchMoved = errors.New("branch moved")
+
+// UpdateFiles commits req.Files to the requested branch (the default branch
+// unless set, created from its head when missing) as a single commit built
+// with the Git Data API, and reports whether anything changed. Files already
+// holding their content are left alone, and when none changed no commit is
+// made. If the branch moved while the commit was built, the commit is
+// rebuilt on the new head and the ref update retried.
+func (g *GitHub) UpdateFiles(ctx context.Context, req UpdateRequest) (bool, error) {
+	repo, err := g.getRepository(ctx, req.Owner, req.Repo, req.Create)
+	if err != nil {
+		return false, err
 	}
+	// a repository created for another user lives under the token's user
+	req.Owner = cmp.Or(repo.GetOwner().GetLogin(), req.Owner)
+	base := repo.GetDefaultBranch()
+	branch := cmp.Or(req.Branch, base)
 
-	readmeFile, _, resp, err := g.client.Repositories.GetContents(ctx, req.Owner, req.Repo, "README.md", &github.RepositoryContentGetOptions{})
-	// if file does not exist, just create it
-	if err != nil || resp == nil || resp.StatusCode != http.StatusOK {
-		if _, _, err := g.client.Repositories.CreateFile(ctx, req.Owner, req.Repo, "README.md", &github.RepositoryContentFileOptions{
-			Message: &req.Message,
-			Content: req.Content,
-		}); err != nil {
-			return fmt.Errorf("cannot create README.md: %w", err)
+	for attempt := 1; ; attempt++ {
+		changed, err := g.commitFiles(ctx, req, branch, base)
+		if !errors.Is(err, errBranchMoved) || attempt == refUpdateAttempts {
+			return changed, err
 		}
-		return nil
+		log.Default().Printf("%s moved while committing, retrying on its new head", branch)
 	}
+}
 
-	// if file exists, update it
-	if err := g.updateReadme(ctx, req, readmeFile.GetSHA()); err != nil {
-		return err
+// getRepository returns the repository owner/name, creating it as described
+// by create when it does not exist. A repository is created under create.Org
+// or the token's user, which need not be owner, so callers take the owner
+// from the returned repository.
+func (g *GitHub) getRepository(ctx context.Context, owner, name string, create *NewRepository) (*github.Repository, error) {
+	repo, _, err := g.client.Repositories.Get(ctx, owner, name)
+	if err == nil {
+		return repo, nil
 	}
-	return nil
+	if create == nil || !hasStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("cannot check repository %s/%s exists: %w", owner, name, err)
+	}
+
+	repo, _, err = g.client.Repositories.Create(ctx, create.Org, &github.Repository{
+		Name:        &name,
+		Description: &create.Description,
+		Homepage:    &create.Homepage,
+		Private:     &create.Private,
+		// the Git Data API cannot commit to an empty repository
+		AutoInit: github.Ptr(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create repository %s/%s: %w", owner, name, err)
+	}
+	log.Default().Printf("created repository %s", repo.GetFullName())
+	if len(create.Topics) > 0 {
+		owner = cmp.Or(repo.GetOwner().GetLogin(), owner)
+		if _, _, err := g.client.Repositories.ReplaceAllTopics(ctx, owner, name, create.Topics); err != nil {
+			return nil, fmt.Errorf("cannot set topics of %s/%s: %w", owner, name, err)
+		}
+	}
+	return repo, nil
 }
 
-func (g *GitHub) updateReadme(ctx context.Context, req UpdateRequest, sha string) error {
-	_, _, err := g.client.Repositories.UpdateFile(ctx, req.Owner, req.Repo, "README.md", &github.RepositoryContentFileOptions{
+// commitFiles makes one attempt of UpdateFiles.
+func (g *GitHub) commitFiles(ctx context.Context, req UpdateRequest, branch, base string) (bool, error) {
+	head, exists, err := g.branchHead(ctx, req.Owner, req.Repo, branch)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		var found bool
+		head, found, err = g.branchHead(ctx, req.Owner, req.Repo, base)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			// the Git Data API needs a commit to build on, so the first
+			// file of an empty repository goes through the contents API
+			if err := g.createFirstFile(ctx, req, base); err != nil {
+				return false, err
+			}
+			_, err := g.commitFiles(ctx, req, branch, base)
+			return true, err
+		}
+	}
+
+	baseTree, blobs, err := g.treeBlobs(ctx, req.Owner, req.Repo, head)
+	if err != nil {
+		return false, err
+	}
+
+	var entries []*github.TreeEntry
+	for _, file := range req.Files {
+		if blobs[file.Path] == gitBlobSHA(file.Content) {
+			continue
+		}
+		blob, _, err := g.client.Git.CreateBlob(ctx, req.Owner, req.Repo, github.Blob{
+			Content:  github.Ptr(base64.StdEncoding.EncodeToString(file.Content)),
+			Encoding: github.Ptr("base64"),
+		})
+		if err != nil {
+			return false, fmt.Errorf("cannot upload %s: %w", file.Path, err)
+		}
+		entries = append(entries, &github.TreeEntry{
+			Path: github.Ptr(file.Path),
+			Mode: github.Ptr("100644"),
+			Type: github.Ptr("blob"),
+			SHA:  blob.SHA,
+		})
+	}
+	if req.Prune != "" {
+		for _, name := range slices.Sorted(maps.Keys(blobs)) {
+			if isStalePage(name, req.Prune, req.Files) {
+				// a nil SHA deletes the file from the base tree
+				entries = append(entries, &github.TreeEntry{
+					Path: github.Ptr(name),
+					Mode: github.Ptr("100644"),
+					Type: github.Ptr("blob"),
+				})
+			}
+		}
+	}
+	if len(entries) == 0 {
+		log.Default().Printf("%s/%s is unchanged, skipping commit", req.Owner, req.Repo)
+		return false, nil
+	}
+
+	newTree, _, err := g.client.Git.CreateTree(ctx, req.Owner, req.Repo, baseTree, entries)
+	if err != nil {
+		return false, fmt.Errorf("cannot create tree: %w", err)
+	}
+	commit, _, err := g.client.Git.CreateCommit(ctx, req.Owner, req.Repo, github.Commit{
 		Message: &req.Message,
-		Content: req.Content,
-		SHA:     &sha,
-	})
-	if err == nil {
-		return nil
+		Tree:    &github.Tree{SHA: newTree.SHA},
+		Parents: []*github.Commit{{SHA: &head}},
+	}, nil)
+	if err != nil {
+		return false, fmt.Errorf("cannot create commit: %w", err)
 	}
-	var ghErr *github.ErrorResponse
-	if !errors.As(err, &ghErr) || ghErr.Response == nil || ghErr.Response.StatusCode != http.StatusConflict {
-		return fmt.Errorf("cannot update README.md: %w", err)
+
+	if exists {
+		_, _, err = g.client.Git.UpdateRef(ctx, req.Owner, req.Repo, "heads/"+branch, github.UpdateRef{SHA: commit.GetSHA()})
+	} else {
+		_, _, err = g.client.Git.CreateRef(ctx, req.Owner, req.Repo, github.CreateRef{Ref: "refs/heads/" + branch, SHA: commit.GetSHA()})
+	}
+	// GitHub rejects a ref update that is not a fast-forward, and a ref
+	// created concurrently, with 422 Unprocessable Entity
+	if hasStatus(err, http.StatusUnprocessableEntity) {
+		return false, fmt.Errorf("cannot update branch %s: %w: %w", branch, errBranchMoved, err)
 	}
-	// the file changed between read and update: re-read the SHA and retry once
-	readmeFile, _, _, err := g.client.Repositories.GetContents(ctx, req.Owner, req.Repo, "README.md", &github.RepositoryContentGetOptions{})
 	if err != nil {
-		return fmt.Errorf("cannot re-read README.md after conflict: %w", err)
+		return false, fmt.Errorf("cannot update branch %s: %w", branch, err)
 	}
-	if _, _, err := g.client.Repositories.UpdateFile(ctx, req.Owner, req.Repo, "README.md", &github.RepositoryContentFileOptions{
+	return true, nil
+}
+
+// createFirstFile commits the first of req.Files to branch of a repository
+// without commits, creating the branch.
+func (g *GitHub) createFirstFile(ctx context.Context, req UpdateRequest, branch string) error {
+	if len(req.Files) == 0 {
+		return fmt.Errorf("cannot read branch %s: not found", branch)
+	}
+	file := req.Files[0]
+	_, _, err := g.client.Repositories.CreateFile(ctx, req.Owner, req.Repo, file.Path, &github.RepositoryContentFileOptions{
 		Message: &req.Message,
-		Content: req.Content,
-		SHA:     readmeFile.SHA,
-	}); err != nil {
-		return fmt.Errorf("cannot update README.md: %w", err)
+		Content: file.Content,
+		Branch:  &branch,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create %s on branch %s: %w", file.Path, branch, err)
 	}
+	log.Default().Printf("%s/%s has no commits, created %s on %s", req.Owner, req.Repo, file.Path, branch)
 	return nil
 }
+
+// treeBlobs returns the tree of commit and the blob SHA of every file in it
+// by path.
+func (g *GitHub) treeBlobs(ctx context.Context, owner, repo, commit string) (string, map[string]string, error) {
+	parent, _, err := g.client.Git.GetCommit(ctx, owner, repo, commit)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot read commit %s: %w", commit, err)
+	}
+	treeSHA := parent.GetTree().GetSHA()
+	tree, _, err := g.client.Git.GetTree(ctx, owner, repo, treeSHA, true)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot read tree %s: %w", treeSHA, err)
+	}
+	blobs := make(map[string]string, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			blobs[entry.GetPath()] = entry.GetSHA()
+		}
+	}
+	return treeSHA, blobs, nil
+}
+
+// branchHead returns the commit at the head of branch, and false when the
+// branch does not exist.
+func (g *GitHub) branchHead(ctx context.Context, owner, repo, branch string) (string, bool, error) {
+	ref, _, err := g.client.Git.GetRef(ctx, owner, repo, "heads/"+branch)
+	// a repository without commits answers 409 Conflict, "Git Repository is
+	// empty", and has no branches
+	if hasStatus(err, http.StatusNotFound) || hasStatus(err, http.StatusConflict) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("cannot read branch %s: %w", branch, err)
+	}
+	return ref.GetObject().GetSHA(), true, nil
+}
+
+// hasStatus reports whether err is a GitHub API error with the given status.
+func hasStatus(err error, status int) bool {
+	var ghErr *github.ErrorResponse
+	return errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == status
+}
+
+// gitBlobSHA returns the object ID git gives a blob holding content, so
+// unchanged files are detected without downloading them.
+func gitBlobSHA(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
-	"io"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"slices"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -173,125 +175,6 @@ func githubClientForMux(t *testing.T, mux *http.ServeMux) *GitHub {
 	return &GitHub{client: client}
 }
 
-func TestUpdateReadmeFileCreatesWhenMissing(t *testing.T) {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(`{}`))
-	})
-	var putBody map[string]any
-	mux.HandleFunc("/repos/o/r/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			w.WriteHeader(http.StatusNotFound)
-			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
-		case http.MethodPut:
-			body, _ := io.ReadAll(r.Body)
-			if err := json.Unmarshal(body, &putBody); err != nil {
-				t.Errorf("bad PUT body: %v", err)
-			}
-			_, _ = w.Write([]byte(`{}`))
-		default:
-			t.Errorf("unexpected method %s", r.Method)
-		}
-	})
-
-	err := githubClientForMux(t, mux).UpdateReadmeFile(context.Background(), UpdateRequest{
-		Owner: "o", Repo: "r", Message: "update stars", Content: []byte("hello"),
-	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	if putBody["message"] != "update stars" {
-		t.Errorf("message = %v, want %q", putBody["message"], "update stars")
-	}
-	// go-github base64-encodes Content before sending
-	if putBody["content"] != base64.StdEncoding.EncodeToString([]byte("hello")) {
-		t.Errorf("content = %v, want base64 of %q", putBody["content"], "hello")
-	}
-}
-
-func TestUpdateReadmeFileUpdatesWithCurrentSHA(t *testing.T) {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(`{}`))
-	})
-	var putBody map[string]any
-	mux.HandleFunc("/repos/o/r/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			_, _ = w.Write([]byte(`{"name":"README.md","sha":"abc123","content":""}`))
-		case http.MethodPut:
-			body, _ := io.ReadAll(r.Body)
-			if err := json.Unmarshal(body, &putBody); err != nil {
-				t.Errorf("bad PUT body: %v", err)
-			}
-			_, _ = w.Write([]byte(`{}`))
-		default:
-			t.Errorf("unexpected method %s", r.Method)
-		}
-	})
-
-	err := githubClientForMux(t, mux).UpdateReadmeFile(context.Background(), UpdateRequest{
-		Owner: "o", Repo: "r", Message: "m", Content: []byte("hello"),
-	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	if putBody["sha"] != "abc123" {
-		t.Errorf("sha = %v, want %q", putBody["sha"], "abc123")
-	}
-}
-
-func TestUpdateReadmeFileRetriesOnceOnConflict(t *testing.T) {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(`{}`))
-	})
-	var gets, puts atomic.Int32
-	var lastSHA any
-	mux.HandleFunc("/repos/o/r/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			if gets.Add(1) == 1 {
-				_, _ = w.Write([]byte(`{"name":"README.md","sha":"stale","content":""}`))
-				return
-			}
-			_, _ = w.Write([]byte(`{"name":"README.md","sha":"fresh","content":""}`))
-		case http.MethodPut:
-			body, _ := io.ReadAll(r.Body)
-			var putBody map[string]any
-			if err := json.Unmarshal(body, &putBody); err != nil {
-				t.Errorf("bad PUT body: %v", err)
-			}
-			lastSHA = putBody["sha"]
-			if puts.Add(1) == 1 {
-				w.WriteHeader(http.StatusConflict)
-				_, _ = w.Write([]byte(`{"message":"is at ... but expected ..."}`))
-				return
-			}
-			_, _ = w.Write([]byte(`{}`))
-		default:
-			t.Errorf("unexpected method %s", r.Method)
-		}
-	})
-
-	err := githubClientForMux(t, mux).UpdateReadmeFile(context.Background(), UpdateRequest{
-		Owner: "o", Repo: "r", Message: "m", Content: []byte("hello"),
-	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	if got := gets.Load(); got != 2 {
-		t.Errorf("GETs = %d, want 2", got)
-	}
-	if got := puts.Load(); got != 2 {
-		t.Errorf("PUTs = %d, want 2", got)
-	}
-	if lastSHA != "fresh" {
-		t.Errorf("retry sha = %v, want %q", lastSHA, "fresh")
-	}
-}
-
 func TestGetRepositoriesMergesStaleLanguageNames(t *testing.T) {
 	oldUsername := username
 	username = "octocat"
@@ -365,3 +248,527 @@ func TestGetRepositoriesReturnsErrorWhenInitialRequestFails(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestGetRepositoriesCapturesStarredAt(t *testing.T) {
+	oldUsername := username
+	username = "octocat"
+	t.Cleanup(func() { username = oldUsername })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/octocat/starred", func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); !strings.Contains(accept, "application/vnd.github.star+json") {
+			t.Errorf("Accept = %q, want star+json media type", accept)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		_, _ = w.Write([]byte(`[
+			{"starred_at":"2026-03-02T10:00:00Z","repo":{"full_name":"a/b","language":"Go"}}
+		]`))
+	})
+
+	langRepoMap, repositories, err := githubClientForMux(t, mux).GetRepositories(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	if len(repositories) != 1 || !repositories[0].StarredAt.Equal(want) {
+		t.Fatalf("repositories = %v, want a/b starred at %s", repositories, want)
+	}
+	if got := langRepoMap["Go"][0].StarredAt; !got.Equal(want) {
+		t.Errorf("language section StarredAt = %s, want %s", got, want)
+	}
+}
+
+func TestNewRepositoryDropsUnidentifiedLicense(t *testing.T) {
+	repo := newRepository(&github.StarredRepository{Repository: &github.Repository{
+		License: &github.License{SPDXID: github.Ptr("NOASSERTION")},
+	}})
+	if repo.License != "" {
+		t.Fatalf("License = %q, want empty", repo.License)
+	}
+}
+
+func TestGetRepositoriesCarriesMetadata(t *testing.T) {
+	oldUsername := username
+	username = "octocat"
+	t.Cleanup(func() { username = oldUsername })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/octocat/starred", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		_, _ = w.Write([]byte(`[{"starred_at":"2026-03-02T10:00:00Z","repo":{
+			"full_name":"hashicorp/terraform",
+			"html_url":"https://github.com/hashicorp/terraform",
+			"homepage":"https://www.terraform.io",
+			"stargazers_count":42000,
+			"forks_count":9000,
+			"topics":["iac","terraform"],
+			"license":{"spdx_id":"BUSL-1.1"},
+			"archived":true,
+			"fork":true,
+			"pushed_at":"2026-10-01T08:00:00Z",
+			"owner":{"login":"hashicorp","html_url":"https://github.com/hashicorp","avatar_url":"https://avatars.githubusercontent.com/u/761456"}
+		}}]`))
+	})
+
+	_, repositories, err := githubClientForMux(t, mux).GetRepositories(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repositories) != 1 {
+		t.Fatalf("repositories = %v, want one", repositories)
+	}
+	got := repositories[0]
+	if got.Homepage != "https://www.terraform.io" || got.Stars != 42000 || got.Forks != 9000 {
+		t.Errorf("homepage/stars/forks = %q/%d/%d", got.Homepage, got.Stars, got.Forks)
+	}
+	if !slices.Equal(got.Topics, []string{"iac", "terraform"}) {
+		t.Errorf("Topics = %v", got.Topics)
+	}
+	if got.License != "BUSL-1.1" || !got.Archived || !got.Fork {
+		t.Errorf("license/archived/fork = %q/%v/%v", got.License, got.Archived, got.Fork)
+	}
+	if want := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC); !got.PushedAt.Equal(want) {
+		t.Errorf("PushedAt = %s, want %s", got.PushedAt, want)
+	}
+	wantOwner := Owner{Login: "hashicorp", URL: "https://github.com/hashicorp", AvatarURL: "https://avatars.githubusercontent.com/u/761456"}
+	if got.Owner != wantOwner {
+		t.Errorf("Owner = %+v, want %+v", got.Owner, wantOwner)
+	}
+}
+
+// gitDataServer fakes the Git Data API of repository o/r, whose default
+// branch is main.
+type gitDataServer struct {
+	// refs maps branches to their head commit.
+	refs map[string]string
+	// files maps paths in every commit's tree to their blob SHA.
+	files map[string]string
+	// contents maps blob SHAs to their content.
+	contents map[string]string
+	// conflicts is the number of ref updates rejected as not fast-forward;
+	// each one moves the branch to "moved".
+	conflicts int
+	// missing makes o/r not found until it is created.
+	missing bool
+	// empty makes o/r have no commits until a file is created through the
+	// contents API.
+	empty bool
+
+	created map[string]any
+	topics  []any
+
+	blobs      []string
+	trees      []map[string]any
+	commits    []map[string]any
+	refUpdates []map[string]any
+}
+
+func newGitDataServer() *gitDataServer {
+	return &gitDataServer{
+		refs:     map[string]string{"main": "head"},
+		files:    map[string]string{},
+		contents: map[string]string{},
+	}
+}
+
+// addFile puts path holding content in every commit's tree.
+func (s *gitDataServer) addFile(path, content string) {
+	sha := gitBlobSHA([]byte(content))
+	s.files[path] = sha
+	s.contents[sha] = content
+}
+
+func (s *gitDataServer) mux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	decode := func(r *http.Request) map[string]any {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("bad %s %s body: %v", r.Method, r.URL.Path, err)
+		}
+		return body
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		if s.missing {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"default_branch":"main"}`))
+	})
+	createRepo := func(w http.ResponseWriter, r *http.Request) {
+		s.created = decode(r)
+		s.created["path"] = r.URL.Path
+		s.missing = false
+		_, _ = w.Write([]byte(`{"full_name":"o/r","owner":{"login":"o"},"default_branch":"main"}`))
+	}
+	mux.HandleFunc("POST /user/repos", createRepo)
+	mux.HandleFunc("POST /orgs/o/repos", createRepo)
+	mux.HandleFunc("PUT /repos/o/r/topics", func(w http.ResponseWriter, r *http.Request) {
+		s.topics, _ = decode(r)["names"].([]any)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("PUT /repos/o/r/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		body := decode(r)
+		if !s.empty {
+			t.Errorf("contents API used on a repository with commits")
+		}
+		content, _ := base64.StdEncoding.DecodeString(body["content"].(string))
+		s.created = body
+		s.created["path"] = r.PathValue("path")
+		s.empty = false
+		s.refs[body["branch"].(string)] = "first"
+		s.addFile(r.PathValue("path"), string(content))
+		_, _ = w.Write([]byte(`{"commit":{"sha":"first"}}`))
+	})
+	mux.HandleFunc("GET /repos/o/r/git/ref/heads/{branch...}", func(w http.ResponseWriter, r *http.Request) {
+		if s.empty {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"Git Repository is empty."}`))
+			return
+		}
+		sha, ok := s.refs[r.PathValue("branch")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"object":{"sha":%q}}`, sha)
+	})
+	mux.HandleFunc("GET /repos/o/r/git/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"sha":%q,"tree":{"sha":"tree-%s"}}`, r.PathValue("sha"), r.PathValue("sha"))
+	})
+	mux.HandleFunc("GET /repos/o/r/git/trees/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("recursive") == "" {
+			t.Error("tree read without recursive")
+		}
+		var entries []map[string]string
+		for path, sha := range s.files {
+			entries = append(entries, map[string]string{"path": path, "type": "blob", "sha": sha})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"sha": r.PathValue("sha"), "tree": entries})
+	})
+	mux.HandleFunc("GET /repos/o/r/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept"); got != "application/vnd.github.v3.raw" {
+			t.Errorf("blob Accept = %q, want raw", got)
+		}
+		content, ok := s.contents[r.PathValue("sha")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc("POST /repos/o/r/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		body := decode(r)
+		content, _ := base64.StdEncoding.DecodeString(body["content"].(string))
+		s.blobs = append(s.blobs, string(content))
+		_, _ = fmt.Fprintf(w, `{"sha":%q}`, gitBlobSHA(content))
+	})
+	mux.HandleFunc("POST /repos/o/r/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		s.trees = append(s.trees, decode(r))
+		_, _ = w.Write([]byte(`{"sha":"new-tree"}`))
+	})
+	mux.HandleFunc("POST /repos/o/r/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		s.commits = append(s.commits, decode(r))
+		_, _ = fmt.Fprintf(w, `{"sha":"commit-%d"}`, len(s.commits))
+	})
+	mux.HandleFunc("PATCH /repos/o/r/git/refs/heads/{branch...}", func(w http.ResponseWriter, r *http.Request) {
+		body := decode(r)
+		if s.conflicts > 0 {
+			s.conflicts--
+			s.refs[r.PathValue("branch")] = "moved"
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Update is not a fast forward"}`))
+			return
+		}
+		body["ref"] = "refs/heads/" + r.PathValue("branch")
+		s.refUpdates = append(s.refUpdates, body)
+		s.refs[r.PathValue("branch")] = body["sha"].(string)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /repos/o/r/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		body := decode(r)
+		s.refUpdates = append(s.refUpdates, body)
+		s.refs[strings.TrimPrefix(body["ref"].(string), "refs/heads/")] = body["sha"].(string)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	return mux
+}
+
+func TestUpdateFilesCommitsChangedFilesOnce(t *testing.T) {
+	server := newGitDataServer()
+	server.files["README.md"] = gitBlobSHA([]byte("old"))
+	server.files["languages/go.md"] = gitBlobSHA([]byte("go"))
+
+	changed, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "o", Repo: "r", Message: "update stars",
+		Files: []outputFile{
+			{Path: "README.md", Content: []byte("new")},
+			{Path: "languages/go.md", Content: []byte("go")},
+			{Path: "languages/rust.md", Content: []byte("rust")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("changed = false, want true")
+	}
+	if !slices.Equal(server.blobs, []string{"new", "rust"}) {
+		t.Errorf("blobs = %q, want the changed files only", server.blobs)
+	}
+	if len(server.trees) != 1 || server.trees[0]["base_tree"] != "tree-head" {
+		t.Fatalf("trees = %v, want one on tree-head", server.trees)
+	}
+	var paths []string
+	for _, entry := range server.trees[0]["tree"].([]any) {
+		paths = append(paths, entry.(map[string]any)["path"].(string))
+	}
+	if !slices.Equal(paths, []string{"README.md", "languages/rust.md"}) {
+		t.Errorf("tree paths = %v", paths)
+	}
+	if len(server.commits) != 1 {
+		t.Fatalf("commits = %v, want one", server.commits)
+	}
+	commit := server.commits[0]
+	if commit["message"] != "update stars" || commit["tree"] != "new-tree" || !slices.Equal(commit["parents"].([]any), []any{"head"}) {
+		t.Errorf("commit = %v", commit)
+	}
+	if len(server.refUpdates) != 1 || server.refUpdates[0]["sha"] != "commit-1" || server.refUpdates[0]["force"] != nil {
+		t.Errorf("ref updates = %v, want a fast-forward to commit-1", server.refUpdates)
+	}
+}
+
+func TestUpdateFilesPrunesStalePages(t *testing.T) {
+	server := newGitDataServer()
+	server.files["docs/stars.md"] = gitBlobSHA([]byte("index"))
+	server.files["docs/languages/go.md"] = gitBlobSHA([]byte("go"))
+	server.files["docs/languages/cobol.md"] = gitBlobSHA([]byte("cobol"))
+	server.files["docs/languages/images/logo.md"] = gitBlobSHA([]byte("logo"))
+	server.files["languages/kept.md"] = gitBlobSHA([]byte("kept"))
+
+	changed, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "o", Repo: "r", Message: "m", Prune: "docs/languages",
+		Files: []outputFile{
+			{Path: "docs/stars.md", Content: []byte("index")},
+			{Path: "docs/languages/go.md", Content: []byte("go")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("changed = false, want true")
+	}
+	if len(server.blobs) != 0 || len(server.trees) != 1 {
+		t.Fatalf("blobs = %q, trees = %v, want only a deletion", server.blobs, server.trees)
+	}
+	entries := server.trees[0]["tree"].([]any)
+	if len(entries) != 1 {
+		t.Fatalf("tree entries = %v, want one", entries)
+	}
+	entry := entries[0].(map[string]any)
+	if sha, ok := entry["sha"]; entry["path"] != "docs/languages/cobol.md" || !ok || sha != nil {
+		t.Errorf("tree entry = %v, want docs/languages/cobol.md deleted", entry)
+	}
+}
+
+func TestUpdateFilesSkipsUnchangedFiles(t *testing.T) {
+	server := newGitDataServer()
+	server.files["README.md"] = gitBlobSHA([]byte("hello"))
+
+	changed, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "o", Repo: "r", Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("hello")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("changed = true, want false")
+	}
+	if len(server.blobs)+len(server.commits)+len(server.refUpdates) != 0 {
+		t.Errorf("wrote blobs %v, commits %v, refs %v", server.blobs, server.commits, server.refUpdates)
+	}
+}
+
+func TestUpdateFilesRetriesWhenBranchMoves(t *testing.T) {
+	server := newGitDataServer()
+	server.conflicts = 1
+
+	changed, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "o", Repo: "r", Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("hello")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("changed = false, want true")
+	}
+	if len(server.commits) != 2 || !slices.Equal(server.commits[1]["parents"].([]any), []any{"moved"}) {
+		t.Fatalf("commits = %v, want the retry on the moved head", server.commits)
+	}
+	if len(server.refUpdates) != 1 || server.refUpdates[0]["sha"] != "commit-2" {
+		t.Errorf("ref updates = %v, want commit-2", server.refUpdates)
+	}
+}
+
+func TestUpdateFilesGivesUpAfterRepeatedConflicts(t *testing.T) {
+	server := newGitDataServer()
+	server.conflicts = refUpdateAttempts
+
+	_, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "o", Repo: "r", Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("hello")}},
+	})
+	if !errors.Is(err, errBranchMoved) {
+		t.Fatalf("err = %v, want errBranchMoved", err)
+	}
+	if len(server.commits) != refUpdateAttempts {
+		t.Errorf("commits = %d, want %d", len(server.commits), refUpdateAttempts)
+	}
+}
+
+func TestUpdateFilesCreatesRequestedBranch(t *testing.T) {
+	server := newGitDataServer()
+
+	_, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "o", Repo: "r", Message: "m", Branch: "stars",
+		Files: []outputFile{{Path: "docs/stars.md", Content: []byte("hello")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(server.commits) != 1 || !slices.Equal(server.commits[0]["parents"].([]any), []any{"head"}) {
+		t.Fatalf("commits = %v, want one on the main head", server.commits)
+	}
+	if len(server.refUpdates) != 1 || server.refUpdates[0]["ref"] != "refs/heads/stars" || server.refUpdates[0]["sha"] != "commit-1" {
+		t.Errorf("ref updates = %v, want refs/heads/stars created at commit-1", server.refUpdates)
+	}
+}
+
+func TestUpdateFilesCommitsToEmptyRepository(t *testing.T) {
+	server := newGitDataServer()
+	server.empty = true
+	delete(server.refs, "main")
+
+	changed, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "o", Repo: "r", Message: "m",
+		Files: []outputFile{
+			{Path: "README.md", Content: []byte("index")},
+			{Path: "languages/go.md", Content: []byte("go")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("changed = false, want true")
+	}
+	if server.created["path"] != "README.md" || server.created["branch"] != "main" || server.created["message"] != "m" {
+		t.Errorf("created file = %v, want README.md on main", server.created)
+	}
+	if !slices.Equal(server.blobs, []string{"go"}) {
+		t.Errorf("blobs = %q, want the remaining file only", server.blobs)
+	}
+	if len(server.commits) != 1 || !slices.Equal(server.commits[0]["parents"].([]any), []any{"first"}) {
+		t.Fatalf("commits = %v, want one on the first commit", server.commits)
+	}
+	if len(server.refUpdates) != 1 || server.refUpdates[0]["sha"] != "commit-1" {
+		t.Errorf("ref updates = %v, want commit-1", server.refUpdates)
+	}
+}
+
+func TestGitBlobSHA(t *testing.T) {
+	// git hash-object on a file holding "hello\n"
+	if got := gitBlobSHA([]byte("hello\n")); got != "ce013625030ba8dba906f756967f9e9ca394464a" {
+		t.Fatalf("gitBlobSHA = %s", got)
+	}
+}
+
+func TestUpdateFilesCreatesMissingRepository(t *testing.T) {
+	for _, tt := range []struct {
+		org  string
+		path string
+	}{
+		{org: "", path: "/user/repos"},
+		{org: "o", path: "/orgs/o/repos"},
+	} {
+		server := newGitDataServer()
+		server.missing = true
+
+		changed, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+			Owner: "o", Repo: "r", Message: "m",
+			Files: []outputFile{{Path: "README.md", Content: []byte("hello")}},
+			Create: &NewRepository{
+				Org:         tt.org,
+				Description: "my stars",
+				Homepage:    "https://example.com",
+				Private:     true,
+				Topics:      []string{"awesome"},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !changed {
+			t.Error("changed = false, want true")
+		}
+		created := server.created
+		if created["path"] != tt.path || created["name"] != "r" || created["description"] != "my stars" ||
+			created["homepage"] != "https://example.com" || created["private"] != true || created["auto_init"] != true {
+			t.Errorf("created repository = %v", created)
+		}
+		if !slices.Equal(server.topics, []any{"awesome"}) {
+			t.Errorf("topics = %v, want [awesome]", server.topics)
+		}
+		if len(server.refUpdates) != 1 {
+			t.Errorf("ref updates = %v, want the initial file committed", server.refUpdates)
+		}
+	}
+}
+
+func TestUpdateFilesFailsOnMissingRepositoryWithoutCreate(t *testing.T) {
+	server := newGitDataServer()
+	server.missing = true
+
+	_, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "o", Repo: "r", Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("hello")}},
+	})
+	if err == nil {
+		t.Fatal("err = nil, want missing repository error")
+	}
+	if server.created != nil {
+		t.Errorf("created repository %v", server.created)
+	}
+}
+
+func TestUpdateFilesWritesWhereRepositoryWasCreated(t *testing.T) {
+	// someone/r does not exist; the token's user is o, so the repository
+	// is created as o/r
+	server := newGitDataServer()
+	server.missing = true
+
+	_, err := githubClientForMux(t, server.mux(t)).UpdateFiles(context.Background(), UpdateRequest{
+		Owner: "someone", Repo: "r", Message: "m",
+		Files:  []outputFile{{Path: "README.md", Content: []byte("hello")}},
+		Create: &NewRepository{Topics: []string{"awesome"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.created["path"] != "/user/repos" {
+		t.Errorf("created repository = %v, want one for the user", server.created)
+	}
+	if !slices.Equal(server.topics, []any{"awesome"}) {
+		t.Errorf("topics = %v, want [awesome] set on o/r", server.topics)
+	}
+	if len(server.refUpdates) != 1 {
+		t.Errorf("ref updates = %v, want the initial file committed to o/r", server.refUpdates)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// starredQuery walks the starred repositories of a user, 100 per page. The
+// rateLimit block lets the fetcher wait for a reset instead of failing once the
+// GraphQL quota is nearly exhausted.
+const starredQuery = `query($login: String!, $cursor: String) {
+  rateLimit { remaining resetAt }
+  user(login: $login) {
+    starredRepositories(first: 100, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      edges {
+        starredAt
+        node {
+          nameWithOwner
+          url
+          description
+          homepageUrl
+          stargazerCount
+          forkCount
+          isArchived
+          isFork
+          pushedAt
+          primaryLanguage { name }
+          licenseInfo { spdxId }
+          repositoryTopics(first: 20) { nodes { topic { name } } }
+          owner { login url avatarUrl }
+        }
+      }
+    }
+  }
+}`
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+type starredResponse struct {
+	Data struct {
+		RateLimit struct {
+			Remaining int       `json:"remaining"`
+			ResetAt   time.Time `json:"resetAt"`
+		} `json:"rateLimit"`
+		User *struct {
+			StarredRepositories struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Edges []starredEdge `json:"edges"`
+			} `json:"starredRepositories"`
+		} `json:"user"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+type starredEdge struct {
+	StarredAt time.Time `json:"starredAt"`
+	Node      struct {
+		NameWithOwner   string    `json:"nameWithOwner"`
+		URL             string    `json:"url"`
+		Description     string    `json:"description"`
+		HomepageURL     string    `json:"homepageUrl"`
+		StargazerCount  int       `json:"stargazerCount"`
+		ForkCount       int       `json:"forkCount"`
+		IsArchived      bool      `json:"isArchived"`
+		IsFork          bool      `json:"isFork"`
+		PushedAt        time.Time `json:"pushedAt"`
+		PrimaryLanguage *struct {
+			Name string `json:"name"`
+		} `json:"primaryLanguage"`
+		LicenseInfo *struct {
+			SPDXID string `json:"spdxId"`
+		} `json:"licenseInfo"`
+		RepositoryTopics struct {
+			Nodes []struct {
+				Topic struct {
+					Name string `json:"name"`
+				} `json:"topic"`
+			} `json:"nodes"`
+		} `json:"repositoryTopics"`
+		Owner struct {
+			Login     string `json:"login"`
+			URL       string `json:"url"`
+			AvatarURL string `json:"avatarUrl"`
+		} `json:"owner"`
+	} `json:"node"`
+}
+
+// GetRepositoriesGraphQL getting repositories from the GitHub GraphQL API. It
+// returns the same data as GetRepositories but walks the star list
+// sequentially with cursor pagination, which costs one GraphQL point per 100
+// stars instead of a burst of concurrent REST requests. The GraphQL API
+// requires an authenticated client.
+func (g *GitHub) GetRepositoriesGraphQL(ctx context.Context) (map[string][]Repository, []Repository, error) {
+	repositories := make([]Repository, 0, repositoriesCount)
+
+	var cursor *string
+	for {
+		page, err := g.fetchStarredGraphQLPage(ctx, username, cursor)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot fetch starred: %w", err)
+		}
+		if page.Data.User == nil {
+			return nil, nil, fmt.Errorf("cannot fetch starred: user %q not found", username)
+		}
+		starred := page.Data.User.StarredRepositories
+		for _, edge := range starred.Edges {
+			repositories = append(repositories, edge.repository())
+		}
+		if !starred.PageInfo.HasNextPage {
+			break
+		}
+		endCursor := starred.PageInfo.EndCursor
+		cursor = &endCursor
+	}
+
+	langRepoMap, repositories := groupByLanguage(repositories)
+	return langRepoMap, repositories, nil
+}
+
+// fetchStarredGraphQLPage fetches one page of starred repositories starting
+// after cursor. Like fetchStarredPage, it waits for the rate limit to reset
+// when the remaining quota is nearly exhausted.
+func (g *GitHub) fetchStarredGraphQLPage(ctx context.Context, username string, cursor *string) (*starredResponse, error) {
+	body := graphQLRequest{
+		Query:     starredQuery,
+		Variables: map[string]any{"login": username, "cursor": cursor},
+	}
+	for {
+		req, err := g.client.NewRequest(ctx, "POST", "graphql", body)
+		if err != nil {
+			return nil, err
+		}
+		var page starredResponse
+		if _, err := g.client.Do(req, &page); err != nil {
+			return nil, err
+		}
+		if len(page.Errors) > 0 {
+			messages := make([]string, 0, len(page.Errors))
+			for _, e := range page.Errors {
+				messages = append(messages, e.Message)
+			}
+			return nil, errors.New(strings.Join(messages, "; "))
+		}
+		if rate := page.Data.RateLimit; rate.Remaining < 10 {
+			if wait := time.Until(rate.ResetAt); wait > 0 {
+				log.Default().Printf("rate limit nearly exhausted, waiting %s until reset", wait.Truncate(time.Second))
+				if err := sleepContext(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		return &page, nil
+	}
+}
+
+// repository converts a starred edge into a Repository.
+func (e starredEdge) repository() Repository {
+	n := e.Node
+	repo := Repository{
+		FullName:    n.NameWithOwner,
+		URL:         n.URL,
+		Description: n.Description,
+		StarredAt:   e.StarredAt,
+		Homepage:    n.HomepageURL,
+		Stars:       n.StargazerCount,
+		Forks:       n.ForkCount,
+		Archived:    n.IsArchived,
+		Fork:        n.IsFork,
+		PushedAt:    n.PushedAt,
+		Owner: Owner{
+			Login:     n.Owner.Login,
+			URL:       n.Owner.URL,
+			AvatarURL: n.Owner.AvatarURL,
+		},
+	}
+	if n.PrimaryLanguage != nil {
+		repo.Language = n.PrimaryLanguage.Name
+	}
+	if n.LicenseInfo != nil {
+		repo.License = spdxID(n.LicenseInfo.SPDXID)
+	}
+	for _, t := range n.RepositoryTopics.Nodes {
+		repo.Topics = append(repo.Topics, t.Topic.Name)
+	}
+	return repo
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetRepositoriesGraphQLFollowsCursor(t *testing.T) {
+	oldUsername := username
+	username = "octocat"
+	t.Cleanup(func() { username = oldUsername })
+
+	var cursors []any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("bad GraphQL body: %v", err)
+			return
+		}
+		if req.Variables["login"] != "octocat" {
+			t.Errorf("login = %v, want octocat", req.Variables["login"])
+		}
+		cursors = append(cursors, req.Variables["cursor"])
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Variables["cursor"] == nil {
+			_, _ = w.Write([]byte(`{"data":{"rateLimit":{"remaining":4999},"user":{"starredRepositories":{
+				"pageInfo":{"hasNextPage":true,"endCursor":"c1"},
+				"edges":[{"starredAt":"2026-03-02T10:00:00Z","node":{
+					"nameWithOwner":"owner/second","url":"https://github.com/owner/second",
+					"description":"second","stargazerCount":7,"forkCount":2,
+					"primaryLanguage":{"name":"VimL"},
+					"licenseInfo":{"spdxId":"MIT"},
+					"repositoryTopics":{"nodes":[{"topic":{"name":"vim"}}]},
+					"owner":{"login":"owner","url":"https://github.com/owner","avatarUrl":"https://avatars.example/owner"}
+				}}]}}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"rateLimit":{"remaining":4998},"user":{"starredRepositories":{
+			"pageInfo":{"hasNextPage":false,"endCursor":"c2"},
+			"edges":[{"starredAt":"2025-01-01T00:00:00Z","node":{
+				"nameWithOwner":"owner/first","url":"https://github.com/owner/first",
+				"primaryLanguage":null,"licenseInfo":{"spdxId":"NOASSERTION"},"repositoryTopics":{"nodes":[]},
+				"owner":{"login":"owner"}
+			}}]}}}}`))
+	})
+
+	langRepoMap, repositories, err := githubClientForMux(t, mux).GetRepositoriesGraphQL(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []any{nil, "c1"}; !slices.Equal(cursors, want) {
+		t.Errorf("cursors = %v, want %v", cursors, want)
+	}
+	got := make([]string, 0, len(repositories))
+	for _, repo := range repositories {
+		got = append(got, repo.FullName)
+	}
+	if want := []string{"owner/first", "owner/second"}; !slices.Equal(got, want) {
+		t.Fatalf("repositories = %v, want %v", got, want)
+	}
+	if want := []string{"Others", "Vim Script"}; !slices.Equal(mapKeys(langRepoMap), want) {
+		t.Errorf("language names = %v, want %v", mapKeys(langRepoMap), want)
+	}
+
+	if first := repositories[0]; first.License != "" {
+		t.Errorf("unidentified license = %q, want empty", first.License)
+	}
+	second := repositories[1]
+	if second.Stars != 7 || second.Forks != 2 || second.License != "MIT" || !slices.Equal(second.Topics, []string{"vim"}) {
+		t.Errorf("metadata = %+v", second)
+	}
+	if want := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC); !second.StarredAt.Equal(want) {
+		t.Errorf("StarredAt = %s, want %s", second.StarredAt, want)
+	}
+	if second.Owner.AvatarURL != "https://avatars.example/owner" {
+		t.Errorf("Owner = %+v", second.Owner)
+	}
+}
+
+func TestGetRepositoriesGraphQLReturnsErrors(t *testing.T) {
+	oldUsername := username
+	username = "ghost-user"
+	t.Cleanup(func() { username = oldUsername })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"user":null},"errors":[{"message":"Could not resolve to a User with the login of 'ghost-user'."}]}`))
+	})
+
+	_, _, err := githubClientForMux(t, mux).GetRepositoriesGraphQL(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Could not resolve") {
+		t.Fatalf("err = %v, want GraphQL error message", err)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+const (
+	// others is the language section for repositories without a language.
+	others = "Others"
+	// uncategorized is the topic section for repositories without topics.
+	uncategorized = "Uncategorized"
+	// unknownDate is the starred-date section for repositories without a
+	// starred_at timestamp.
+	unknownDate = "Unknown"
+)
+
+// sectionOrders lists the accepted --section-order values.
+var sectionOrders = []string{"alphabetical", "count", "custom"}
+
+// groupRepositories buckets repositories into sections named by keys. A
+// repository appears in every section keys returns for it. Each section is
+// sorted by FullName.
+func groupRepositories(repositories []Repository, keys func(Repository) []string) map[string][]Repository {
+	groups := make(map[string][]Repository, langReposCount)
+	for _, repo := range repositories {
+		for _, key := range keys(repo) {
+			groups[key] = append(groups[key], repo)
+		}
+	}
+
+	for _, repositories := range groups {
+		slices.SortFunc(repositories, func(a, b Repository) int {
+			return cmp.Compare(a.FullName, b.FullName)
+		})
+	}
+
+	return groups
+}
+
+// groupByTopic buckets repositories by their GitHub topics. A repository
+// appears in the sections of its first maxTopics topics (all of them when
+// maxTopics is 0); repositories without topics go to "Uncategorized".
+func groupByTopic(repositories []Repository, maxTopics int) map[string][]Repository {
+	return groupRepositories(repositories, func(repo Repository) []string {
+		topics := repo.Topics
+		if maxTopics > 0 && len(topics) > maxTopics {
+			topics = topics[:maxTopics]
+		}
+		if len(topics) == 0 {
+			return []string{uncategorized}
+		}
+		return topics
+	})
+}
+
+// groupByOwner buckets repositories by the login of the user or organization
+// owning them.
+func groupByOwner(repositories []Repository) map[string][]Repository {
+	return groupRepositories(repositories, func(repo Repository) []string {
+		if repo.Owner.Login != "" {
+			return []string{repo.Owner.Login}
+		}
+		owner, _, _ := strings.Cut(repo.FullName, "/")
+		return []string{owner}
+	})
+}
+
+// dateLayouts maps --date-granularity values to the layout naming sections.
+var dateLayouts = map[string]string{
+	"year":  "2006",
+	"month": "2006-01",
+}
+
+// Section is a named group of repositories rendered in order. Anchor is the
+// fragment linking to the section heading; File is the page holding the
+// section with --split.
+type Section struct {
+	Name   string
+	Anchor string
+	File   string
+	Repos  []Repository
+}
+
+// newSections turns groups into sections ordered by order: "alphabetical"
+// (case-insensitive), "count" (most repositories first) or "custom" (the
+// names listed in custom first, in that order, then the rest alphabetically).
+// Catch-all sections such as "Others" always go last.
+func newSections(groups map[string][]Repository, order string, custom []string) []Section {
+	sections := make([]Section, 0, len(groups))
+	for name, repos := range groups {
+		sections = append(sections, Section{Name: name, Repos: repos})
+	}
+
+	alphabetical := func(a, b Section) int {
+		return cmp.Or(
+			cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)),
+			cmp.Compare(a.Name, b.Name),
+		)
+	}
+	rank := func(s Section) int {
+		if i := slices.Index(custom, s.Name); i >= 0 {
+			return i
+		}
+		return len(custom)
+	}
+	slices.SortFunc(sections, func(a, b Section) int {
+		if c := cmp.Compare(catchAllRank(a.Name), catchAllRank(b.Name)); c != 0 {
+			return c
+		}
+		switch order {
+		case "count":
+			return cmp.Or(cmp.Compare(len(b.Repos), len(a.Repos)), alphabetical(a, b))
+		case "custom":
+			return cmp.Or(cmp.Compare(rank(a), rank(b)), alphabetical(a, b))
+		}
+		return alphabetical(a, b)
+	})
+
+	setAnchors(sections)
+	return sections
+}
+
+// catchAllRank returns 1 for sections collecting repositories that have no
+// value for the grouping key and 0 otherwise, so sorting by it keeps the
+// catch-all sections last.
+func catchAllRank(name string) int {
+	switch name {
+	case others, uncategorized, unknownDate:
+		return 1
+	}
+	return 0
+}
+
+// setAnchors fills in the Anchor of every section with the ID GitHub gives
+// its heading. The embedded template renders the "Contents" heading before the
+// sections, so a section with the same slug gets the "-1" suffix.
+func setAnchors(sections []Section) {
+	s := newSlugger()
+	s.slug("Contents")
+	for i := range sections {
+		sections[i].Anchor = s.slug(sections[i].Name)
+	}
+}
+
+// groupByStarredDate buckets repositories by when they were starred, using
+// layout to name sections (e.g. "2026" or "2026-10"). Sections are returned
+// newest first with "Unknown" last; repositories within a section are ordered
+// by StarredAt, most recent first.
+func groupByStarredDate(repositories []Repository, layout string) []Section {
+	groups := groupRepositories(repositories, func(repo Repository) []string {
+		if repo.StarredAt.IsZero() {
+			return []string{unknownDate}
+		}
+		return []string{repo.StarredAt.UTC().Format(layout)}
+	})
+
+	sections := make([]Section, 0, len(groups))
+	for name, repos := range groups {
+		sections = append(sections, Section{Name: name, Repos: byStarredAt(repos)})
+	}
+	slices.SortFunc(sections, func(a, b Section) int {
+		return cmp.Or(
+			cmp.Compare(catchAllRank(a.Name), catchAllRank(b.Name)),
+			cmp.Compare(b.Name, a.Name),
+		)
+	})
+	setAnchors(sections)
+	return sections
+}
+
+// repositoryOrders maps --sort-by values to the order of repositories within
+// a section. Counts and timestamps sort largest or most recent first; ties are
+// broken by FullName.
+var repositoryOrders = map[string]func(a, b Repository) int{
+	"name": func(a, b Repository) int {
+		return cmp.Compare(a.FullName, b.FullName)
+	},
+	"stars": func(a, b Repository) int {
+		return cmp.Or(cmp.Compare(b.Stars, a.Stars), cmp.Compare(a.FullName, b.FullName))
+	},
+	"forks": func(a, b Repository) int {
+		return cmp.Or(cmp.Compare(b.Forks, a.Forks), cmp.Compare(a.FullName, b.FullName))
+	},
+	"starred": func(a, b Repository) int {
+		return cmp.Or(b.StarredAt.Compare(a.StarredAt), cmp.Compare(a.FullName, b.FullName))
+	},
+	"pushed": func(a, b Repository) int {
+		return cmp.Or(b.PushedAt.Compare(a.PushedAt), cmp.Compare(a.FullName, b.FullName))
+	},
+}
+
+// sortRepositories sorts repositories in place by the --sort-by key by,
+// reversing the order when reverse is set.
+func sortRepositories(repositories []Repository, by string, reverse bool) {
+	compare := repositoryOrders[by]
+	slices.SortFunc(repositories, func(a, b Repository) int {
+		if reverse {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+}
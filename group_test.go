@@ -0,0 +1,173 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGroupByTopic(t *testing.T) {
+	repositories := []Repository{
+		{FullName: "b/k8s", Topics: []string{"kubernetes", "go", "cli", "operator"}},
+		{FullName: "a/parser", Topics: []string{"parsing", "go"}},
+		{FullName: "c/plain"},
+	}
+
+	topicRepoMap := groupByTopic(repositories, 3)
+
+	if want := []string{"Uncategorized", "cli", "go", "kubernetes", "parsing"}; !slices.Equal(mapKeys(topicRepoMap), want) {
+		t.Fatalf("topics = %v, want %v", mapKeys(topicRepoMap), want)
+	}
+	if _, ok := topicRepoMap["operator"]; ok {
+		t.Error("topics beyond the cap must not get a section")
+	}
+	got := make([]string, 0, 2)
+	for _, repo := range topicRepoMap["go"] {
+		got = append(got, repo.FullName)
+	}
+	if want := []string{"a/parser", "b/k8s"}; !slices.Equal(got, want) {
+		t.Errorf("go section = %v, want %v", got, want)
+	}
+	if plain := topicRepoMap[uncategorized]; len(plain) != 1 || plain[0].FullName != "c/plain" {
+		t.Errorf("Uncategorized section = %v, want c/plain", plain)
+	}
+}
+
+func TestGroupByTopicWithoutLimit(t *testing.T) {
+	topicRepoMap := groupByTopic([]Repository{
+		{FullName: "b/k8s", Topics: []string{"kubernetes", "go", "cli", "operator"}},
+	}, 0)
+	if want := []string{"cli", "go", "kubernetes", "operator"}; !slices.Equal(mapKeys(topicRepoMap), want) {
+		t.Fatalf("topics = %v, want %v", mapKeys(topicRepoMap), want)
+	}
+}
+
+func TestGroupByOwner(t *testing.T) {
+	ownerRepoMap := groupByOwner([]Repository{
+		{FullName: "hashicorp/vault", Owner: Owner{Login: "hashicorp"}},
+		{FullName: "juev/starred", Owner: Owner{Login: "juev"}},
+		{FullName: "hashicorp/consul", Owner: Owner{Login: "hashicorp"}},
+		{FullName: "golang/go"},
+	})
+
+	if want := []string{"golang", "hashicorp", "juev"}; !slices.Equal(mapKeys(ownerRepoMap), want) {
+		t.Fatalf("owners = %v, want %v", mapKeys(ownerRepoMap), want)
+	}
+	got := make([]string, 0, 2)
+	for _, repo := range ownerRepoMap["hashicorp"] {
+		got = append(got, repo.FullName)
+	}
+	if want := []string{"hashicorp/consul", "hashicorp/vault"}; !slices.Equal(got, want) {
+		t.Errorf("hashicorp section = %v, want %v", got, want)
+	}
+}
+
+func TestGroupByStarredDate(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 12, 0, 0, 0, time.UTC) }
+	repositories := []Repository{
+		{FullName: "a/old", StarredAt: day(2024, 5, 1)},
+		{FullName: "a/unknown"},
+		{FullName: "a/jan", StarredAt: day(2026, 1, 3)},
+		{FullName: "a/oct", StarredAt: day(2026, 10, 2)},
+		{FullName: "a/mid", StarredAt: day(2025, 6, 9)},
+	}
+
+	names := func(sections []Section) []string {
+		got := make([]string, 0, len(sections))
+		for _, s := range sections {
+			got = append(got, s.Name)
+		}
+		return got
+	}
+
+	years := groupByStarredDate(repositories, dateLayouts["year"])
+	if want := []string{"2026", "2025", "2024", "Unknown"}; !slices.Equal(names(years), want) {
+		t.Fatalf("year sections = %v, want %v", names(years), want)
+	}
+	got := make([]string, 0, 2)
+	for _, repo := range years[0].Repos {
+		got = append(got, repo.FullName)
+	}
+	if want := []string{"a/oct", "a/jan"}; !slices.Equal(got, want) {
+		t.Errorf("2026 section = %v, want most recently starred first %v", got, want)
+	}
+
+	months := groupByStarredDate(repositories, dateLayouts["month"])
+	if want := []string{"2026-10", "2026-01", "2025-06", "2024-05", "Unknown"}; !slices.Equal(names(months), want) {
+		t.Fatalf("month sections = %v, want %v", names(months), want)
+	}
+}
+
+func TestNewSections(t *testing.T) {
+	groups := map[string][]Repository{
+		"Others":     {{FullName: "o/1"}, {FullName: "o/2"}, {FullName: "o/3"}, {FullName: "o/4"}},
+		"Go":         {{FullName: "g/1"}, {FullName: "g/2"}},
+		"Rust":       {{FullName: "r/1"}, {FullName: "r/2"}, {FullName: "r/3"}},
+		"jq":         {{FullName: "j/1"}},
+		"Vim Script": {{FullName: "v/1"}},
+	}
+	names := func(sections []Section) []string {
+		got := make([]string, 0, len(sections))
+		for _, s := range sections {
+			got = append(got, s.Name)
+		}
+		return got
+	}
+
+	cases := []struct {
+		name   string
+		order  string
+		custom []string
+		want   []string
+	}{
+		{"alphabetical", "alphabetical", nil, []string{"Go", "jq", "Rust", "Vim Script", "Others"}},
+		{"count", "count", nil, []string{"Rust", "Go", "jq", "Vim Script", "Others"}},
+		{"custom", "custom", []string{"Vim Script", "Others", "Go", "Missing"}, []string{"Vim Script", "Go", "jq", "Rust", "Others"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := names(newSections(groups, tc.order, tc.custom)); !slices.Equal(got, tc.want) {
+				t.Fatalf("sections = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	sections := newSections(groups, "alphabetical", nil)
+	if sections[3].Anchor != "vim-script" || len(sections[3].Repos) != 1 {
+		t.Errorf("Vim Script section = %+v, want anchor vim-script and one repository", sections[3])
+	}
+}
+
+func TestSortRepositories(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	repositories := []Repository{
+		{FullName: "a/popular", Stars: 900, Forks: 10, StarredAt: day(1), PushedAt: day(9)},
+		{FullName: "c/forked", Stars: 5, Forks: 300, StarredAt: day(3), PushedAt: day(2)},
+		{FullName: "b/recent", Stars: 5, Forks: 1, StarredAt: day(7), PushedAt: day(4)},
+	}
+
+	cases := []struct {
+		by      string
+		reverse bool
+		want    []string
+	}{
+		{"name", false, []string{"a/popular", "b/recent", "c/forked"}},
+		{"name", true, []string{"c/forked", "b/recent", "a/popular"}},
+		{"stars", false, []string{"a/popular", "b/recent", "c/forked"}},
+		{"stars", true, []string{"c/forked", "b/recent", "a/popular"}},
+		{"forks", false, []string{"c/forked", "a/popular", "b/recent"}},
+		{"starred", false, []string{"b/recent", "c/forked", "a/popular"}},
+		{"pushed", false, []string{"a/popular", "b/recent", "c/forked"}},
+	}
+	for _, tc := range cases {
+		sorted := slices.Clone(repositories)
+		sortRepositories(sorted, tc.by, tc.reverse)
+		got := make([]string, 0, len(sorted))
+		for _, repo := range sorted {
+			got = append(got, repo.FullName)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("sortRepositories(%q, reverse=%v) = %v, want %v", tc.by, tc.reverse, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"html/template"
+	"io"
+
+	_ "embed"
+)
+
+var (
+	//go:embed templates/html/page.tmpl
+	htmlPage string
+	//go:embed templates/html/page.css
+	htmlCSS string
+	//go:embed templates/html/page.js
+	htmlJS string
+)
+
+// htmlData is the data passed to the HTML page template.
+type htmlData struct {
+	templateData
+	// Languages lists the language sections for the sidebar.
+	Languages []Section
+	CSS       template.CSS
+	JS        template.JS
+}
+
+// renderHTML writes a self-contained HTML page listing all repositories in a
+// sortable table, with a language sidebar and free-text search. Styles and
+// scripts are inlined so the file can be served from any static host.
+func renderHTML(w io.Writer, data templateData) error {
+	temp, err := template.New("html").Funcs(template.FuncMap{
+		"formatDate": formatDate,
+		"language":   languageSection,
+	}).Parse(htmlPage)
+	if err != nil {
+		return err
+	}
+	return temp.Execute(w, htmlData{
+		templateData: data,
+		Languages:    newSections(data.LangRepoMap, "alphabetical", nil),
+		CSS:          template.CSS(htmlCSS),
+		JS:           template.JS(htmlJS),
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderHTML(t *testing.T) {
+	repos := []Repository{
+		{
+			FullName: "a/vim", URL: "https://github.com/a/vim", Language: "VimL", Stars: 42,
+			Description: "<script>alert(1)</script>", Archived: true,
+			StarredAt: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{FullName: "x/y", URL: "https://github.com/x/y"},
+	}
+	langRepoMap, repositories := groupByLanguage(repos)
+
+	var sb strings.Builder
+	if err := renderHTML(&sb, templateData{UserName: "juev", LangRepoMap: langRepoMap, Repositories: repositories}); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Awesome Stars of juev</title>",
+		`<tr data-language="Vim Script">`,
+		`<tr data-language="Others">`,
+		`<a href="#" data-language="Vim Script">Vim Script <span class="count">1</span></a>`,
+		`<a href="https://github.com/a/vim">a/vim</a> <span class="badge">archived</span>`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		`<td data-value="42">42</td>`,
+		"<td>Vim Script</td>",
+		"<td>2026-03-02</td>",
+		htmlCSS,
+		htmlJS,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<script>alert(1)</script>") {
+		t.Error("descriptions must be HTML-escaped")
+	}
+	if strings.Index(out, `data-language="Others">Others`) < strings.Index(out, `data-language="Vim Script">Vim Script`) {
+		t.Error("Others must be the last sidebar entry")
+	}
+}
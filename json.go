@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonSchemaVersion is bumped whenever a field of the --format json output is
+// renamed, removed or changes meaning. Adding fields keeps the version.
+const jsonSchemaVersion = 1
+
+// jsonOutput is the document written by --format json:
+//
+//	{
+//	  "schema_version": 1,
+//	  "user": "juev",
+//	  "group_by": "language",
+//	  "repositories": [{"full_name": "owner/name", "url": "...", ...}],
+//	  "sections": [{"name": "Go", "anchor": "go", "repositories": ["owner/name"]}]
+//	}
+//
+// repositories holds every starred repository in the --sort-by order.
+// sections is present only when the output is grouped (--sort or --group-by)
+// and lists the full names of the repositories in each section, in order.
+type jsonOutput struct {
+	SchemaVersion int              `json:"schema_version"`
+	User          string           `json:"user"`
+	GroupBy       string           `json:"group_by,omitempty"`
+	Repositories  []jsonRepository `json:"repositories"`
+	Sections      []jsonSection    `json:"sections,omitempty"`
+}
+
+type jsonRepository struct {
+	FullName    string     `json:"full_name"`
+	URL         string     `json:"url"`
+	Language    string     `json:"language"`
+	Description string     `json:"description"`
+	Homepage    string     `json:"homepage"`
+	Stars       int        `json:"stars"`
+	Forks       int        `json:"forks"`
+	Topics      []string   `json:"topics"`
+	License     string     `json:"license"`
+	Archived    bool       `json:"archived"`
+	Fork        bool       `json:"fork"`
+	StarredAt   *time.Time `json:"starred_at"`
+	PushedAt    *time.Time `json:"pushed_at"`
+	Owner       jsonOwner  `json:"owner"`
+}
+
+type jsonOwner struct {
+	Login     string `json:"login"`
+	URL       string `json:"url"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type jsonSection struct {
+	Name         string   `json:"name"`
+	Anchor       string   `json:"anchor"`
+	Repositories []string `json:"repositories"`
+}
+
+// renderJSON writes data as an indented jsonOutput document.
+func renderJSON(w io.Writer, data templateData) error {
+	out := jsonOutput{
+		SchemaVersion: jsonSchemaVersion,
+		User:          data.UserName,
+		Repositories:  make([]jsonRepository, 0, len(data.Repositories)),
+	}
+	for _, repo := range data.Repositories {
+		out.Repositories = append(out.Repositories, newJSONRepository(repo))
+	}
+	if data.SortCmd {
+		out.GroupBy = data.GroupBy
+		out.Sections = make([]jsonSection, 0, len(data.Sections))
+		for _, section := range data.Sections {
+			names := make([]string, 0, len(section.Repos))
+			for _, repo := range section.Repos {
+				names = append(names, repo.FullName)
+			}
+			out.Sections = append(out.Sections, jsonSection{Name: section.Name, Anchor: section.Anchor, Repositories: names})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func newJSONRepository(repo Repository) jsonRepository {
+	topics := repo.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+	return jsonRepository{
+		FullName:    repo.FullName,
+		URL:         repo.URL,
+		Language:    repo.Language,
+		Description: repo.Description,
+		Homepage:    repo.Homepage,
+		Stars:       repo.Stars,
+		Forks:       repo.Forks,
+		Topics:      topics,
+		License:     repo.License,
+		Archived:    repo.Archived,
+		Fork:        repo.Fork,
+		StarredAt:   optionalTime(repo.StarredAt),
+		PushedAt:    optionalTime(repo.PushedAt),
+		Owner: jsonOwner{
+			Login:     repo.Owner.Login,
+			URL:       repo.Owner.URL,
+			AvatarURL: repo.Owner.AvatarURL,
+		},
+	}
+}
+
+// optionalTime returns nil for the zero time so it is encoded as null.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
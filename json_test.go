@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderJSON(t *testing.T) {
+	repos := []Repository{
+		{
+			FullName: "a/b", URL: "https://github.com/a/b", Language: "Go", Description: "d",
+			Stars: 3, Topics: []string{"cli"}, License: "MIT",
+			StarredAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+			Owner:     Owner{Login: "a", URL: "https://github.com/a"},
+		},
+		{FullName: "x/y", URL: "https://github.com/x/y"},
+	}
+	langRepoMap := map[string][]Repository{"Go": repos[:1], "Others": repos[1:]}
+
+	var sb strings.Builder
+	err := renderJSON(&sb, templateData{
+		SortCmd:      true,
+		GroupBy:      "language",
+		UserName:     "juev",
+		Repositories: repos,
+		LangRepoMap:  langRepoMap,
+		Sections:     newSections(langRepoMap, "alphabetical", nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, sb.String())
+	}
+	if got["schema_version"] != float64(jsonSchemaVersion) || got["user"] != "juev" || got["group_by"] != "language" {
+		t.Errorf("header = %v/%v/%v", got["schema_version"], got["user"], got["group_by"])
+	}
+
+	var out jsonOutput
+	if err := json.Unmarshal([]byte(sb.String()), &out); err != nil {
+		t.Fatal(err)
+	}
+	first := out.Repositories[0]
+	if first.FullName != "a/b" || first.Stars != 3 || first.License != "MIT" || first.Owner.Login != "a" {
+		t.Errorf("first repository = %+v", first)
+	}
+	if first.StarredAt == nil || !first.StarredAt.Equal(repos[0].StarredAt) {
+		t.Errorf("starred_at = %v, want %s", first.StarredAt, repos[0].StarredAt)
+	}
+	if !strings.Contains(sb.String(), `"starred_at": null`) || !strings.Contains(sb.String(), `"topics": []`) {
+		t.Errorf("missing values must be null or empty lists:\n%s", sb.String())
+	}
+	if len(out.Sections) != 2 || out.Sections[0].Name != "Go" || out.Sections[1].Repositories[0] != "x/y" {
+		t.Errorf("sections = %+v", out.Sections)
+	}
+}
+
+func TestRenderJSONFlatHasNoSections(t *testing.T) {
+	var sb strings.Builder
+	if err := renderJSON(&sb, templateData{UserName: "juev"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), "sections") {
+		t.Errorf("flat output must not contain sections:\n%s", sb.String())
+	}
+	if !strings.Contains(sb.String(), `"repositories": []`) {
+		t.Errorf("empty star list must encode as []:\n%s", sb.String())
+	}
+}
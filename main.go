@@ -1,12 +1,16 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"slices"
 	"strings"
 	"text/template"
+	"time"
 
 	_ "embed"
 
@@ -17,18 +21,68 @@ import (
 var content []byte
 
 var (
-	username   string
-	token      string
-	repository string
-	message    string
-	sortCmd    bool
-	help       bool
-	versionCmd bool
-	buffer     strings.Builder
-	version    string
-	commit     string
-	date       string
-	tpl        string
+	//go:embed templates/org.tmpl
+	orgTemplate []byte
+	//go:embed templates/asciidoc.tmpl
+	asciidocTemplate []byte
+)
+
+// builtinTemplate is an output template embedded in the binary.
+type builtinTemplate struct {
+	content []byte
+	// file is the file written to the repository with --repository.
+	file string
+}
+
+// builtinTemplates maps --template-name values to the embedded templates.
+var builtinTemplates = map[string]builtinTemplate{
+	"markdown": {content: content, file: "README.md"},
+	"org":      {content: orgTemplate, file: "README.org"},
+	"asciidoc": {content: asciidocTemplate, file: "README.adoc"},
+}
+
+var (
+	username     string
+	token        string
+	repository   string
+	message      string
+	sortCmd      bool
+	help         bool
+	versionCmd   bool
+	buffer       strings.Builder
+	version      string
+	commit       string
+	date         string
+	tpl          string
+	templateName string
+	backend      string
+	groupBy      string
+	maxTopics    int
+	dateGroup    string
+	sectionOrder string
+	sectionNames []string
+	sortBy       string
+	reverse      bool
+	format       string
+	columns      []string
+	feedSize     int
+	opmlReleases bool
+	sqlitePath   string
+	exportCmd    bool
+	split        bool
+	output       string
+	pullRequest  bool
+	targetPath   string
+	branch       string
+)
+
+// settings of the repository created when --repository does not exist
+var (
+	org             string
+	repoDescription string
+	repoHomepage    string
+	repoTopics      []string
+	repoVisibility  string
 )
 
 func init() {
@@ -36,8 +90,32 @@ func init() {
 	flag.StringVarP(&token, "token", "t", "", "GitHub token")
 	flag.StringVarP(&repository, "repository", "r", "", "repository name (e.g., \"awesome-stars\")")
 	flag.StringVarP(&message, "message", "m", "update stars", "commit message")
+	flag.StringVarP(&output, "output", "o", "", "write to this file, or directory with --split, instead of stdout")
+	flag.StringVar(&org, "org", "", "organization owning the repository instead of the user")
+	flag.StringVar(&repoDescription, "repo-description", "A curated list of my GitHub stars!", "description of the repository when it is created")
+	flag.StringVar(&repoHomepage, "repo-homepage", "", "homepage of the repository when it is created")
+	flag.StringSliceVar(&repoTopics, "repo-topics", []string{"awesome", "awesome-list"}, "topics of the repository when it is created")
+	flag.StringVar(&repoVisibility, "repo-visibility", "public", "visibility of the repository when it is created: public or private")
+	flag.StringVar(&targetPath, "path", "", "file written to the repository (e.g., \"docs/stars.md\"), by default named after the format")
+	flag.StringVar(&branch, "branch", "", "branch written to the repository, created from the default branch when missing")
+	flag.BoolVar(&pullRequest, "pull-request", false, "publish to the repository through a pull request instead of committing to the default branch")
 	flag.StringVarP(&tpl, "template", "T", "", "template file to customize output")
+	flag.StringVar(&templateName, "template-name", "markdown", "built-in template: markdown, org or asciidoc")
+	flag.StringVarP(&format, "format", "f", "template", "output format: template, json, csv, tsv, html, atom, rss, bookmarks or opml")
+	flag.StringSliceVar(&columns, "columns", defaultCSVColumns, "csv and tsv columns")
+	flag.IntVar(&feedSize, "feed-size", 20, "number of recently starred repositories in atom and rss feeds")
+	flag.BoolVar(&opmlReleases, "opml-releases", false, "link the releases feed of every repository in opml output")
+	flag.StringVarP(&backend, "backend", "b", "rest", "API used to fetch stars: rest or graphql")
 	flag.BoolVarP(&sortCmd, "sort", "s", false, "sort by language")
+	flag.BoolVar(&split, "split", false, "write README.md plus one page per language under languages/ (implies --sort)")
+	flag.StringVarP(&groupBy, "group-by", "g", "language", "group sorted output by language, topic, owner or starred-date (implies --sort)")
+	flag.IntVar(&maxTopics, "max-topics", 3, "maximum number of topic sections a repository appears in, 0 for no limit")
+	flag.StringVar(&dateGroup, "date-granularity", "year", "starred-date section size: year or month")
+	flag.StringVar(&sectionOrder, "section-order", "alphabetical", "section order: alphabetical, count or custom")
+	flag.StringSliceVar(&sectionNames, "sections", nil, "section names listed first with --section-order custom (e.g., \"Go,Rust\")")
+	flag.StringVar(&sortBy, "sort-by", "name", "repository order within sections: name, stars, starred, pushed or forks")
+	flag.BoolVar(&reverse, "reverse", false, "reverse the repository order")
+	flag.StringVar(&sqlitePath, "sqlite", "", "SQLite database written by the export command")
 	flag.BoolVarP(&help, "help", "h", false, "show this message and exit")
 	flag.BoolVarP(&versionCmd, "version", "v", false, "show the version and exit")
 }
@@ -58,11 +136,123 @@ func configure() {
 		usage()
 		os.Exit(0)
 	}
+	switch flag.Arg(0) {
+	case "":
+	case "export":
+		exportCmd = true
+		if sqlitePath == "" {
+			fmt.Println("Error: export need set sqlite")
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Error: unknown command %q\n", flag.Arg(0))
+		os.Exit(1)
+	}
+	if repository != "" && output != "" {
+		fmt.Println("Error: repository and output are mutually exclusive")
+		os.Exit(1)
+	}
+	if pullRequest && repository == "" {
+		fmt.Println("Error: pull-request need set repository")
+		os.Exit(1)
+	}
+	switch repoVisibility {
+	case "public", "private":
+	default:
+		fmt.Printf("Error: unknown repo-visibility %q, want public or private\n", repoVisibility)
+		os.Exit(1)
+	}
+	if targetPath != "" && !fs.ValidPath(targetPath) {
+		fmt.Printf("Error: path %q must be relative to the repository root\n", targetPath)
+		os.Exit(1)
+	}
 	if repository != "" && token == "" {
 		fmt.Println("Error: repository need set token")
 		os.Exit(1)
 	}
+	switch backend {
+	case "rest":
+	case "graphql":
+		if token == "" {
+			fmt.Println("Error: graphql backend need set token")
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Error: unknown backend %q, want rest or graphql\n", backend)
+		os.Exit(1)
+	}
+	switch groupBy {
+	case "language", "topic", "owner", "starred-date":
+	default:
+		fmt.Printf("Error: unknown group-by %q, want language, topic, owner or starred-date\n", groupBy)
+		os.Exit(1)
+	}
+	if !slices.Contains(sectionOrders, sectionOrder) {
+		fmt.Printf("Error: unknown section-order %q, want alphabetical, count or custom\n", sectionOrder)
+		os.Exit(1)
+	}
+	if flag.CommandLine.Changed("sections") && !flag.CommandLine.Changed("section-order") {
+		sectionOrder = "custom"
+	}
+	if _, ok := outputFormats[format]; !ok {
+		fmt.Printf("Error: unknown format %q, want template, json, csv, tsv, html, atom, rss, bookmarks or opml\n", format)
+		os.Exit(1)
+	}
+	if maxTopics < 0 {
+		fmt.Println("Error: max-topics must not be negative")
+		os.Exit(1)
+	}
+	if feedSize < 1 {
+		fmt.Println("Error: feed-size must be positive")
+		os.Exit(1)
+	}
+	for _, column := range columns {
+		if _, ok := csvColumns[column]; !ok {
+			fmt.Printf("Error: unknown column %q\n", column)
+			os.Exit(1)
+		}
+	}
+	if _, ok := repositoryOrders[sortBy]; !ok {
+		fmt.Printf("Error: unknown sort-by %q, want name, stars, starred, pushed or forks\n", sortBy)
+		os.Exit(1)
+	}
+	// the star journal lists the most recently starred repositories first
+	if groupBy == "starred-date" && !flag.CommandLine.Changed("sort-by") {
+		sortBy = "starred"
+	}
+	if _, ok := dateLayouts[dateGroup]; !ok {
+		fmt.Printf("Error: unknown date-granularity %q, want year or month\n", dateGroup)
+		os.Exit(1)
+	}
+	if flag.CommandLine.Changed("group-by") {
+		sortCmd = true
+	}
+	if split {
+		if format != "template" || groupBy != "language" {
+			fmt.Println("Error: split supports only the template format grouped by language")
+			os.Exit(1)
+		}
+		if templateName != "markdown" {
+			fmt.Println("Error: split supports only the markdown template")
+			os.Exit(1)
+		}
+		if output == "" && repository == "" {
+			fmt.Println("Error: split need set output or repository")
+			os.Exit(1)
+		}
+		sortCmd = true
+	}
 
+	builtin, ok := builtinTemplates[templateName]
+	if !ok {
+		fmt.Printf("Error: unknown template-name %q, want markdown, org or asciidoc\n", templateName)
+		os.Exit(1)
+	}
+	content = builtin.content
+	if tpl != "" && flag.CommandLine.Changed("template-name") {
+		fmt.Println("Error: template and template-name are mutually exclusive")
+		os.Exit(1)
+	}
 	if tpl != "" {
 		var err error
 		content, err = os.ReadFile(tpl)
@@ -83,37 +273,131 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	langRepoMap, repositories, err := client.GetRepositories(ctx)
+	getRepositories := client.GetRepositories
+	if backend == "graphql" {
+		getRepositories = client.GetRepositoriesGraphQL
+	}
+	langRepoMap, repositories, err := getRepositories(ctx)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	temp, err := parseTemplate(content)
-	if err != nil {
-		fmt.Printf("Error: template parse failed: %s\n", err)
-		os.Exit(1)
+	if exportCmd {
+		if err := exportSQLite(ctx, sqlitePath, repositories, time.Now()); err != nil {
+			log.Fatalln(err)
+		}
+		return
 	}
 
 	data := templateData{
 		SortCmd:      sortCmd,
+		GroupBy:      groupBy,
 		LangRepoMap:  langRepoMap,
 		UserName:     username,
 		Repositories: repositories,
 	}
+	switch groupBy {
+	case "language":
+		data.Sections = newSections(langRepoMap, sectionOrder, sectionNames)
+	case "topic":
+		data.TopicRepoMap = groupByTopic(repositories, maxTopics)
+		data.Sections = newSections(data.TopicRepoMap, sectionOrder, sectionNames)
+	case "owner":
+		data.OwnerRepoMap = groupByOwner(repositories)
+		data.Sections = newSections(data.OwnerRepoMap, sectionOrder, sectionNames)
+	case "starred-date":
+		// starred-date sections keep their chronological order
+		data.DateSections = groupByStarredDate(repositories, dateLayouts[dateGroup])
+		data.Sections = data.DateSections
+	}
+	// sections share their slices with the maps, so this orders both
+	sortRepositories(data.Repositories, sortBy, reverse)
+	for _, section := range data.Sections {
+		sortRepositories(section.Repos, sortBy, reverse)
+	}
+	for _, repos := range langRepoMap {
+		sortRepositories(repos, sortBy, reverse)
+	}
 
-	if err := temp.Execute(&buffer, data); err != nil {
-		log.Fatalln(err)
+	outputFormat := outputFormats[format]
+	path := outputFormat.file
+	if format == "template" && tpl == "" {
+		path = builtinTemplates[templateName].file
+	}
+	if targetPath != "" {
+		path = targetPath
+	}
+	var files []outputFile
+	var prune string
+	if split {
+		prune = splitPages(path)
+		files, err = renderSplit(data, path)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	} else {
+		if err := outputFormat.render(&buffer, data); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		files = []outputFile{{Path: path, Content: []byte(buffer.String())}}
 	}
 
 	if repository == "" {
-		fmt.Println(buffer.String())
+		if output == "" {
+			// print the same bytes --output would write
+			fmt.Print(buffer.String())
+			return
+		}
+		var changed bool
+		if split {
+			changed, err = writeFiles(output, files, prune)
+		} else {
+			changed, err = writeFile(output, files[0].Content)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if !changed {
+			log.Println("output is unchanged")
+			os.Exit(exitUnchanged)
+		}
 		return
 	}
-	if err := client.UpdateReadmeFile(ctx, UpdateRequest{
-		Owner:   username,
+	create := &NewRepository{
+		Org:         org,
+		Description: repoDescription,
+		Homepage:    repoHomepage,
+		Private:     repoVisibility == "private",
+		Topics:      repoTopics,
+	}
+	if pullRequest {
+		url, err := client.PublishPullRequest(ctx, PullRequest{
+			Owner:   cmp.Or(org, username),
+			Repo:    repository,
+			Branch:  cmp.Or(branch, pullRequestBranch),
+			Message: message,
+			Files:   files,
+			Prune:   prune,
+			Create:  create,
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if url != "" {
+			fmt.Println(url)
+		}
+		return
+	}
+	if _, err := client.UpdateFiles(ctx, UpdateRequest{
+		Owner:   cmp.Or(org, username),
 		Repo:    repository,
 		Message: message,
-		Content: []byte(buffer.String()),
+		Files:   files,
+		Branch:  branch,
+		Prune:   prune,
+		Create:  create,
 	}); err != nil {
 		log.Fatalln(err)
 	}
@@ -134,7 +418,15 @@ func buildVersionString(version, commit, date string) string {
 // templateData is the data passed to the output template.
 type templateData struct {
 	SortCmd      bool
+	GroupBy      string
 	LangRepoMap  map[string][]Repository
+	TopicRepoMap map[string][]Repository
+	OwnerRepoMap map[string][]Repository
+	DateSections []Section
+	Sections     []Section
+	// Split is set when sections are rendered on their own pages, linked
+	// through Section.File.
+	Split        bool
 	UserName     string
 	Repositories []Repository
 }
@@ -142,19 +434,46 @@ type templateData struct {
 // parseTemplate parses the output template with the built-in function map.
 func parseTemplate(content []byte) (*template.Template, error) {
 	funcMap := template.FuncMap{
-		"toLink": func(lang string) string { return strings.ToLower(strings.ReplaceAll(lang, " ", "-")) },
+		"toLink":         toLink,
+		"formatDate":     formatDate,
+		"byStarredAt":    byStarredAt,
+		"escapeMarkdown": escapeMarkdown,
+		"escapeHTML":     escapeHTML,
+		"escapeOrg":      escapeOrg,
+		"escapeAsciiDoc": escapeAsciiDoc,
 	}
 	return template.New("starred").Funcs(funcMap).Parse(string(content))
 }
 
+// formatDate formats t with the given Go time layout, e.g.
+// {{ .StarredAt | formatDate "2006-01-02" }}. A zero time renders as "".
+func formatDate(layout string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// byStarredAt returns a copy of repos ordered by StarredAt, most recently
+// starred first. Repositories with equal timestamps keep their order.
+func byStarredAt(repos []Repository) []Repository {
+	sorted := slices.Clone(repos)
+	slices.SortStableFunc(sorted, func(a, b Repository) int {
+		return b.StarredAt.Compare(a.StarredAt)
+	})
+	return sorted
+}
+
 func usage() {
 	fmt.Println(`
 Usage: starred [OPTIONS]
+       starred export --sqlite FILE [OPTIONS]
 
   Starred: A tool to create your own Awesome List using your GitHub stars!
 
   example:
     starred --username juev --sort > README.md
+    starred export --sqlite stars.db --username juev
 
 Options:`)
 	flag.PrintDefaults()
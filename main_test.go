@@ -3,6 +3,7 @@ package main
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildVersionString(t *testing.T) {
@@ -56,7 +57,27 @@ func TestParseTemplateToLink(t *testing.T) {
 	if err := temp.Execute(&sb, nil); err != nil {
 		t.Fatalf("unexpected execute error: %v", err)
 	}
-	if got := sb.String(); got != "visual-basic-.net" {
-		t.Fatalf("output = %q, want %q", got, "visual-basic-.net")
+	if got := sb.String(); got != "visual-basic-net" {
+		t.Fatalf("output = %q, want %q", got, "visual-basic-net")
+	}
+}
+
+func TestParseTemplateFormatDate(t *testing.T) {
+	temp, err := parseTemplate([]byte(`{{ range byStarredAt .Repositories }}{{ .FullName }} {{ .StarredAt | formatDate "2006-01-02" }};{{ end }}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var sb strings.Builder
+	data := templateData{Repositories: []Repository{
+		{FullName: "a/old", StarredAt: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{FullName: "a/unknown"},
+		{FullName: "a/new", StarredAt: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+	}}
+	if err := temp.Execute(&sb, data); err != nil {
+		t.Fatalf("unexpected execute error: %v", err)
+	}
+	want := "a/new 2026-03-02;a/old 2024-01-05;a/unknown ;"
+	if got := sb.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
 	}
 }
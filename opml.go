@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text        string        `xml:"text,attr"`
+	Type        string        `xml:"type,attr,omitempty"`
+	Description string        `xml:"description,attr,omitempty"`
+	HTMLURL     string        `xml:"htmlUrl,attr,omitempty"`
+	XMLURL      string        `xml:"xmlUrl,attr,omitempty"`
+	Outlines    []opmlOutline `xml:"outline"`
+}
+
+// renderOPML writes an OPML 2.0 outline with one entry per language holding
+// its repositories. With --opml-releases every repository links its
+// releases.atom feed, so importing the file into a feed reader subscribes to
+// the releases of all starred repositories.
+func renderOPML(w io.Writer, data templateData) error {
+	doc := opmlDocument{Version: "2.0", Title: feedTitle(data.UserName)}
+	for _, section := range newSections(data.LangRepoMap, "alphabetical", nil) {
+		language := opmlOutline{Text: section.Name}
+		for _, repo := range section.Repos {
+			outline := opmlOutline{
+				Text:        repo.FullName,
+				Description: strings.Join(strings.Fields(repo.Description), " "),
+				HTMLURL:     repo.URL,
+			}
+			if opmlReleases {
+				outline.Type = "rss"
+				outline.XMLURL = repo.URL + "/releases.atom"
+			}
+			language.Outlines = append(language.Outlines, outline)
+		}
+		doc.Body = append(doc.Body, language)
+	}
+	return writeXML(w, doc)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func opmlFixture() templateData {
+	langRepoMap, repositories := groupByLanguage([]Repository{
+		{FullName: "a/go", URL: "https://github.com/a/go", Language: "Go", Description: "fast & small"},
+		{FullName: "x/y", URL: "https://github.com/x/y"},
+	})
+	return templateData{UserName: "juev", LangRepoMap: langRepoMap, Repositories: repositories}
+}
+
+func TestRenderOPML(t *testing.T) {
+	var sb strings.Builder
+	if err := renderOPML(&sb, opmlFixture()); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>GitHub stars of juev</title>
+  </head>
+  <body>
+    <outline text="Go">
+      <outline text="a/go" description="fast &amp; small" htmlUrl="https://github.com/a/go"></outline>
+    </outline>
+    <outline text="Others">
+      <outline text="x/y" htmlUrl="https://github.com/x/y"></outline>
+    </outline>
+  </body>
+</opml>
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("opml =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderOPMLWithReleaseFeeds(t *testing.T) {
+	oldReleases := opmlReleases
+	opmlReleases = true
+	t.Cleanup(func() { opmlReleases = oldReleases })
+
+	var sb strings.Builder
+	if err := renderOPML(&sb, opmlFixture()); err != nil {
+		t.Fatal(err)
+	}
+	want := `<outline text="x/y" type="rss" htmlUrl="https://github.com/x/y" xmlUrl="https://github.com/x/y/releases.atom"></outline>`
+	if !strings.Contains(sb.String(), want) {
+		t.Fatalf("output missing %q:\n%s", want, sb.String())
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// exitUnchanged is the exit status when --output left every file as it was,
+// so cron jobs and Makefiles can tell a no-op run from an update.
+const exitUnchanged = 3
+
+// writeFile replaces name with content by writing a temporary file next to it
+// and renaming it into place, so readers never see a partial file. Missing
+// parent directories are created. When name already holds content it is left
+// untouched and writeFile reports false.
+func writeFile(name string, content []byte) (bool, error) {
+	mode := fs.FileMode(0o644)
+	old, err := os.ReadFile(name)
+	switch {
+	case err == nil:
+		if bytes.Equal(old, content) {
+			return false, nil
+		}
+		if info, err := os.Stat(name); err == nil {
+			mode = info.Mode().Perm()
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return false, fmt.Errorf("cannot read %s: %w", name, err)
+	}
+
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, fmt.Errorf("cannot create directory for %s: %w", name, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(name)+".*")
+	if err != nil {
+		return false, fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	// removing after the rename is a no-op
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return false, fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return false, fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// writeFiles writes files below dir with writeFile and reports whether any
+// of them changed. When prune is set, Markdown files directly in the prune
+// directory below dir that are not among files are removed, so pages of
+// sections that no longer exist go away.
+func writeFiles(dir string, files []outputFile, prune string) (bool, error) {
+	changed := false
+	for _, file := range files {
+		written, err := writeFile(filepath.Join(dir, filepath.FromSlash(file.Path)), file.Content)
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || written
+	}
+	if prune == "" {
+		return changed, nil
+	}
+
+	pruneDir := filepath.Join(dir, filepath.FromSlash(prune))
+	entries, err := os.ReadDir(pruneDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return changed, nil
+	}
+	if err != nil {
+		return changed, fmt.Errorf("cannot read %s: %w", pruneDir, err)
+	}
+	for _, entry := range entries {
+		name := path.Join(prune, entry.Name())
+		if !entry.Type().IsRegular() || !isStalePage(name, prune, files) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(pruneDir, entry.Name())); err != nil {
+			return changed, fmt.Errorf("cannot remove stale page: %w", err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
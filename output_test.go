@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "docs", "README.md")
+
+	changed, err := writeFile(name, []byte("stars"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("new file reported unchanged")
+	}
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "stars" {
+		t.Fatalf("content = %q, want %q", got, "stars")
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Fatalf("mode = %v, want 0644", info.Mode().Perm())
+	}
+
+	changed, err = writeFile(name, []byte("stars"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Fatal("identical content reported changed")
+	}
+
+	changed, err = writeFile(name, []byte("more stars"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("new content reported unchanged")
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("directory holds %d entries, want only README.md", len(entries))
+	}
+}
+
+func TestWriteFileKeepsMode(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(name, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeFile(name, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []outputFile{
+		{Path: "README.md", Content: []byte("index")},
+		{Path: "languages/go.md", Content: []byte("go")},
+	}
+	changed, err := writeFiles(dir, files, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("new files reported unchanged")
+	}
+	for _, file := range files {
+		got, err := os.ReadFile(filepath.Join(dir, file.Path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(file.Content) {
+			t.Errorf("%s = %q, want %q", file.Path, got, file.Content)
+		}
+	}
+
+	files[1].Content = []byte("gopher")
+	if changed, err := writeFiles(dir, files, ""); err != nil || !changed {
+		t.Fatalf("writeFiles = %v, %v, want one file changed", changed, err)
+	}
+	if changed, err := writeFiles(dir, files, ""); err != nil || changed {
+		t.Fatalf("writeFiles = %v, %v, want nothing changed", changed, err)
+	}
+}
+
+func TestWriteFilesPrunesStalePages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"languages/go.md", "languages/cobol.md", "languages/notes.txt", "other.md"} {
+		if _, err := writeFile(filepath.Join(dir, name), []byte("old")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := []outputFile{
+		{Path: "README.md", Content: []byte("index")},
+		{Path: "languages/go.md", Content: []byte("old")},
+	}
+	if _, err := writeFiles(dir, files, "languages"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{
+		"languages/go.md":     true,
+		"languages/cobol.md":  false,
+		"languages/notes.txt": true,
+		"other.md":            true,
+	} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", name, exists, want)
+		}
+	}
+
+	// removing a page alone is a change
+	if err := os.WriteFile(filepath.Join(dir, "languages", "cobol.md"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := writeFiles(dir, files, "languages"); err != nil || !changed {
+		t.Fatalf("writeFiles = %v, %v, want the stale page removed", changed, err)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// pullRequestBranch is the branch --pull-request commits to.
+const pullRequestBranch = "starred/update-stars"
+
+// PullRequest describes files published through a pull request.
+type PullRequest struct {
+	Owner string
+	Repo  string
+	// Branch is the head branch, created from the default branch when missing.
+	Branch string
+	// Message is the commit message and the pull request title.
+	Message string
+	Files   []outputFile
+	// Prune is passed on to UpdateRequest.Prune.
+	Prune string
+	// Create describes the repository to create when it does not exist.
+	Create *NewRepository
+}
+
+// PublishPullRequest commits req.Files to req.Branch and opens a pull request
+// into the default branch, or updates the one already open. Without an open
+// pull request the branch is first reset to the default branch. The body lists
+// the repositories added and removed compared to the default branch. A
+// missing repository is created as described by req.Create. It returns the
+// pull request URL, or "" when the default branch is up to date.
+func (g *GitHub) PublishPullRequest(ctx context.Context, req PullRequest) (string, error) {
+	repo, err := g.getRepository(ctx, req.Owner, req.Repo, req.Create)
+	if err != nil {
+		return "", err
+	}
+	req.Owner = cmp.Or(repo.GetOwner().GetLogin(), req.Owner)
+	base := repo.GetDefaultBranch()
+	if req.Branch == base {
+		return "", fmt.Errorf("cannot open a pull request from %s into itself", base)
+	}
+
+	// compare blob SHAs with the default branch, and download only the
+	// files that changed to summarize them
+	head, found, err := g.branchHead(ctx, req.Owner, req.Repo, base)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("cannot read branch %s: not found", base)
+	}
+	_, blobs, err := g.treeBlobs(ctx, req.Owner, req.Repo, head)
+	if err != nil {
+		return "", err
+	}
+	var before, after [][]byte
+	changed := false
+	for _, file := range req.Files {
+		after = append(after, file.Content)
+		sha, ok := blobs[file.Path]
+		if sha == gitBlobSHA(file.Content) {
+			before = append(before, file.Content)
+			continue
+		}
+		changed = true
+		if ok {
+			content, err := g.blobContent(ctx, req.Owner, req.Repo, file.Path, sha)
+			if err != nil {
+				return "", err
+			}
+			before = append(before, content)
+		}
+	}
+	if req.Prune != "" {
+		for _, name := range slices.Sorted(maps.Keys(blobs)) {
+			if !isStalePage(name, req.Prune, req.Files) {
+				continue
+			}
+			changed = true
+			content, err := g.blobContent(ctx, req.Owner, req.Repo, name, blobs[name])
+			if err != nil {
+				return "", err
+			}
+			before = append(before, content)
+		}
+	}
+	if !changed {
+		log.Default().Printf("%s/%s is up to date, no pull request needed", req.Owner, req.Repo)
+		return "", nil
+	}
+
+	pulls, _, err := g.client.PullRequests.List(ctx, req.Owner, req.Repo, &github.PullRequestListOptions{
+		State: "open",
+		Head:  req.Owner + ":" + req.Branch,
+		Base:  base,
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot list pull requests: %w", err)
+	}
+	// without an open pull request the branch is left over from a merged or
+	// closed one; committing on top of it would conflict with the default
+	// branch, so start it over from there
+	if len(pulls) == 0 {
+		if err := g.resetBranch(ctx, req.Owner, req.Repo, req.Branch, base); err != nil {
+			return "", err
+		}
+	}
+
+	// UpdateFiles creates the branch on the first commit
+	if _, err := g.UpdateFiles(ctx, UpdateRequest{
+		Owner:   req.Owner,
+		Repo:    req.Repo,
+		Message: req.Message,
+		Files:   req.Files,
+		Branch:  req.Branch,
+		Prune:   req.Prune,
+	}); err != nil {
+		return "", err
+	}
+
+	body := pullRequestBody(repositoryChanges(before, after))
+	if len(pulls) > 0 {
+		pull, _, err := g.client.PullRequests.Edit(ctx, req.Owner, req.Repo, pulls[0].GetNumber(), &github.PullRequest{
+			Title: &req.Message,
+			Body:  &body,
+		})
+		if err != nil {
+			return "", fmt.Errorf("cannot update pull request #%d: %w", pulls[0].GetNumber(), err)
+		}
+		return pull.GetHTMLURL(), nil
+	}
+	pull, _, err := g.client.PullRequests.Create(ctx, req.Owner, req.Repo, github.CreatePullRequest{
+		Title: &req.Message,
+		Head:  req.Branch,
+		Base:  base,
+		Body:  &body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot create pull request: %w", err)
+	}
+	return pull.GetHTMLURL(), nil
+}
+
+// resetBranch moves branch to the head of base, discarding its commits. A
+// branch other than pullRequestBranch is only reset when base already
+// contains all of its commits. A missing branch is left for UpdateFiles to
+// create.
+func (g *GitHub) resetBranch(ctx context.Context, owner, repo, branch, base string) error {
+	head, exists, err := g.branchHead(ctx, owner, repo, branch)
+	if err != nil || !exists {
+		return err
+	}
+	baseHead, found, err := g.branchHead(ctx, owner, repo, base)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("cannot read branch %s: not found", base)
+	}
+	if head == baseHead {
+		return nil
+	}
+	// the tool's own branch only ever holds generated commits; any other
+	// branch is the user's and may only be reset once base contains it
+	if branch != pullRequestBranch {
+		comparison, _, err := g.client.Repositories.CompareCommits(ctx, owner, repo, base, branch, nil)
+		if err != nil {
+			return fmt.Errorf("cannot compare branch %s with %s: %w", branch, base, err)
+		}
+		switch comparison.GetStatus() {
+		case "behind", "identical":
+		default:
+			return fmt.Errorf("branch %s has commits not merged into %s, refusing to reset it", branch, base)
+		}
+	}
+	if _, _, err := g.client.Git.UpdateRef(ctx, owner, repo, "heads/"+branch, github.UpdateRef{
+		SHA:   baseHead,
+		Force: github.Ptr(true),
+	}); err != nil {
+		return fmt.Errorf("cannot reset branch %s to %s: %w", branch, base, err)
+	}
+	return nil
+}
+
+// blobContent downloads the blob sha holding path. Raw blobs have no size
+// limit, unlike the contents API.
+func (g *GitHub) blobContent(ctx context.Context, owner, repo, path, sha string) ([]byte, error) {
+	content, _, err := g.client.Git.GetBlobRaw(ctx, owner, repo, sha)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// repositoryURL matches links to GitHub repositories in any output format.
+var repositoryURL = regexp.MustCompile(`https://github\.com/([\w.-]+/[\w.-]+)`)
+
+// repositoryChanges returns the repositories linked from after but not from
+// before, and the other way round, sorted by name. Links shared by both, like
+// the ones in the template header, cancel out.
+func repositoryChanges(before, after [][]byte) (added, removed []string) {
+	links := func(contents [][]byte) map[string]bool {
+		names := make(map[string]bool)
+		for _, content := range contents {
+			for _, m := range repositoryURL.FindAllSubmatch(content, -1) {
+				names[string(m[1])] = true
+			}
+		}
+		return names
+	}
+	old, current := links(before), links(after)
+	for name := range current {
+		if !old[name] {
+			added = append(added, name)
+		}
+	}
+	for name := range old {
+		if !current[name] {
+			removed = append(removed, name)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
+
+// pullRequestBody summarizes the added and removed repositories.
+func pullRequestBody(added, removed []string) string {
+	var sb strings.Builder
+	sb.WriteString("Generated by [juev/starred](https://github.com/juev/starred).\n")
+	if len(added) == 0 && len(removed) == 0 {
+		sb.WriteString("\nNo repositories were added or removed.\n")
+	}
+	for _, list := range []struct {
+		title string
+		names []string
+	}{{"Added", added}, {"Removed", removed}} {
+		if len(list.names) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n### %s (%d)\n\n", list.title, len(list.names))
+		for _, name := range list.names {
+			fmt.Fprintf(&sb, "- [%s](https://github.com/%s)\n", name, name)
+		}
+	}
+	return sb.String()
+}
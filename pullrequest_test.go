@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pullRequestMux serves server's repository, whose default branch holds
+// README.md with content.
+func pullRequestMux(t *testing.T, server *gitDataServer, content string) *http.ServeMux {
+	t.Helper()
+	server.addFile("README.md", content)
+	return server.mux(t)
+}
+
+func TestPublishPullRequestCreatesBranchAndPullRequest(t *testing.T) {
+	server := newGitDataServer()
+	mux := pullRequestMux(t, server, "- [a/old](https://github.com/a/old)\n- [a/kept](https://github.com/a/kept)\n")
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("head"); got != "o:starred/update-stars" {
+			t.Errorf("head = %q, want %q", got, "o:starred/update-stars")
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	var pullBody map[string]any
+	mux.HandleFunc("POST /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &pullBody); err != nil {
+			t.Errorf("bad POST body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"number":1,"html_url":"https://github.com/o/r/pull/1"}`))
+	})
+
+	url, err := githubClientForMux(t, mux).PublishPullRequest(context.Background(), PullRequest{
+		Owner:   "o",
+		Repo:    "r",
+		Branch:  pullRequestBranch,
+		Message: "update stars",
+		Files: []outputFile{{
+			Path:    "README.md",
+			Content: []byte("- [a/kept](https://github.com/a/kept)\n- [a/new](https://github.com/a/new)\n"),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/o/r/pull/1" {
+		t.Errorf("url = %q", url)
+	}
+	if len(server.refUpdates) != 1 || server.refUpdates[0]["ref"] != "refs/heads/starred/update-stars" {
+		t.Errorf("ref updates = %v, want starred/update-stars created", server.refUpdates)
+	}
+	if pullBody["head"] != "starred/update-stars" || pullBody["base"] != "main" || pullBody["title"] != "update stars" {
+		t.Errorf("pull request = %v", pullBody)
+	}
+	body, _ := pullBody["body"].(string)
+	for _, want := range []string{"### Added (1)\n\n- [a/new](https://github.com/a/new)", "### Removed (1)\n\n- [a/old](https://github.com/a/old)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestPublishPullRequestUpdatesOpenPullRequest(t *testing.T) {
+	server := newGitDataServer()
+	server.refs[pullRequestBranch] = "branch"
+	mux := pullRequestMux(t, server, "old")
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"number":7}]`))
+	})
+	var edited bool
+	mux.HandleFunc("PATCH /repos/o/r/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		edited = true
+		_, _ = w.Write([]byte(`{"number":7,"html_url":"https://github.com/o/r/pull/7"}`))
+	})
+
+	url, err := githubClientForMux(t, mux).PublishPullRequest(context.Background(), PullRequest{
+		Owner: "o", Repo: "r", Branch: pullRequestBranch, Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("new")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !edited || url != "https://github.com/o/r/pull/7" {
+		t.Errorf("edited = %v, url = %q", edited, url)
+	}
+	if len(server.commits) != 1 || !slices.Equal(server.commits[0]["parents"].([]any), []any{"branch"}) {
+		t.Errorf("commits = %v, want one on the existing branch", server.commits)
+	}
+}
+
+func TestPublishPullRequestResetsBranchWithoutOpenPullRequest(t *testing.T) {
+	server := newGitDataServer()
+	server.refs[pullRequestBranch] = "merged"
+	mux := pullRequestMux(t, server, "old")
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("POST /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number":8,"html_url":"https://github.com/o/r/pull/8"}`))
+	})
+
+	_, err := githubClientForMux(t, mux).PublishPullRequest(context.Background(), PullRequest{
+		Owner: "o", Repo: "r", Branch: pullRequestBranch, Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("new")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(server.refUpdates) != 2 {
+		t.Fatalf("ref updates = %v, want a reset and a commit", server.refUpdates)
+	}
+	if reset := server.refUpdates[0]; reset["sha"] != "head" || reset["force"] != true {
+		t.Errorf("reset = %v, want a forced update to the main head", reset)
+	}
+	if len(server.commits) != 1 || !slices.Equal(server.commits[0]["parents"].([]any), []any{"head"}) {
+		t.Errorf("commits = %v, want one on the main head", server.commits)
+	}
+}
+
+func TestPublishPullRequestKeepsUnmergedUserBranch(t *testing.T) {
+	server := newGitDataServer()
+	server.refs["develop"] = "work"
+	mux := pullRequestMux(t, server, "old")
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/o/r/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.PathValue("basehead"); got != "main...develop" {
+			t.Errorf("compare %q, want main...develop", got)
+		}
+		_, _ = w.Write([]byte(`{"status":"diverged","ahead_by":2,"behind_by":1}`))
+	})
+
+	_, err := githubClientForMux(t, mux).PublishPullRequest(context.Background(), PullRequest{
+		Owner: "o", Repo: "r", Branch: "develop", Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("new")}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not merged") {
+		t.Fatalf("err = %v, want unmerged branch error", err)
+	}
+	if len(server.refUpdates)+len(server.commits) != 0 {
+		t.Errorf("wrote refs %v, commits %v", server.refUpdates, server.commits)
+	}
+}
+
+func TestPublishPullRequestResetsMergedUserBranch(t *testing.T) {
+	server := newGitDataServer()
+	server.refs["develop"] = "old-head"
+	mux := pullRequestMux(t, server, "old")
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/o/r/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"behind","ahead_by":0,"behind_by":3}`))
+	})
+	mux.HandleFunc("POST /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number":9}`))
+	})
+
+	_, err := githubClientForMux(t, mux).PublishPullRequest(context.Background(), PullRequest{
+		Owner: "o", Repo: "r", Branch: "develop", Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("new")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(server.refUpdates) != 2 || server.refUpdates[0]["sha"] != "head" {
+		t.Errorf("ref updates = %v, want a reset to the main head and a commit", server.refUpdates)
+	}
+}
+
+func TestPublishPullRequestSkipsUpToDateRepository(t *testing.T) {
+	server := newGitDataServer()
+	mux := pullRequestMux(t, server, "same")
+
+	url, err := githubClientForMux(t, mux).PublishPullRequest(context.Background(), PullRequest{
+		Owner: "o", Repo: "r", Branch: pullRequestBranch, Message: "m",
+		Files: []outputFile{{Path: "README.md", Content: []byte("same")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "" {
+		t.Errorf("url = %q, want none", url)
+	}
+	if len(server.commits)+len(server.refUpdates) != 0 {
+		t.Errorf("wrote commits %v, refs %v", server.commits, server.refUpdates)
+	}
+}
+
+func TestPublishPullRequestSummarizesLargeFiles(t *testing.T) {
+	// the contents API does not return files over 1 MB
+	padding := strings.Repeat(" ", 1<<20)
+	server := newGitDataServer()
+	server.addFile("stars.json", `{"url":"https://github.com/a/old"}`+padding)
+	mux := server.mux(t)
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	var pullBody map[string]any
+	mux.HandleFunc("POST /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &pullBody); err != nil {
+			t.Errorf("bad POST body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"number":1}`))
+	})
+
+	_, err := githubClientForMux(t, mux).PublishPullRequest(context.Background(), PullRequest{
+		Owner: "o", Repo: "r", Branch: pullRequestBranch, Message: "m",
+		Files: []outputFile{{Path: "stars.json", Content: []byte(`{"url":"https://github.com/a/new"}` + padding)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := pullBody["body"].(string)
+	for _, want := range []string{"- [a/new](https://github.com/a/new)", "- [a/old](https://github.com/a/old)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestPublishPullRequestCountsStalePages(t *testing.T) {
+	server := newGitDataServer()
+	server.addFile("README.md", "index")
+	server.addFile("languages/cobol.md", "- [a/cobol](https://github.com/a/cobol)\n")
+	mux := server.mux(t)
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	var pullBody map[string]any
+	mux.HandleFunc("POST /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &pullBody); err != nil {
+			t.Errorf("bad POST body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"number":1}`))
+	})
+
+	_, err := githubClientForMux(t, mux).PublishPullRequest(context.Background(), PullRequest{
+		Owner: "o", Repo: "r", Branch: pullRequestBranch, Message: "m", Prune: "languages",
+		Files: []outputFile{{Path: "README.md", Content: []byte("index")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := pullBody["body"].(string); !strings.Contains(body, "### Removed (1)\n\n- [a/cobol](https://github.com/a/cobol)") {
+		t.Errorf("body does not list the removed page:\n%s", body)
+	}
+}
+
+func TestRepositoryChanges(t *testing.T) {
+	header := "[awesome](https://github.com/sindresorhus/awesome) by [juev](https://github.com/juev)\n"
+	before := [][]byte{[]byte(header + "- [a/b](https://github.com/a/b)\n- [c/d](https://github.com/c/d)\n")}
+	after := [][]byte{
+		[]byte(header),
+		[]byte("- [c/d](https://github.com/c/d) – see https://github.com/e/f.js\n"),
+	}
+	added, removed := repositoryChanges(before, after)
+	if !slices.Equal(added, []string{"e/f.js"}) || !slices.Equal(removed, []string{"a/b"}) {
+		t.Fatalf("added = %v, removed = %v", added, removed)
+	}
+}
+
+func TestPullRequestBodyWithoutChanges(t *testing.T) {
+	body := pullRequestBody(nil, nil)
+	if !strings.Contains(body, "No repositories were added or removed.") {
+		t.Fatalf("body =\n%s", body)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// toLink returns the anchor GitHub generates for a Markdown heading with the
+// given text, ignoring other headings in the document: the text is
+// lowercased, every character except letters, marks, numbers, "_", "-" and
+// spaces is dropped, and each space becomes "-". So "C++" links to "#c" and
+// "Visual Basic .NET" to "#visual-basic-net".
+func toLink(heading string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			sb.WriteRune('-')
+		case r == '-', unicode.IsLetter(r), unicode.IsMark(r), unicode.IsNumber(r), unicode.Is(unicode.Pc, r):
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// slugger generates heading anchors for a whole document the way GitHub does:
+// when a slug was already used, it appends "-1", "-2" and so on, so "C++" and
+// "C#" in the same document link to "#c" and "#c-1".
+type slugger struct {
+	occurrences map[string]int
+}
+
+func newSlugger() *slugger {
+	return &slugger{occurrences: make(map[string]int)}
+}
+
+// slug returns the anchor of the next heading with the given text.
+func (s *slugger) slug(heading string) string {
+	base := toLink(heading)
+	slug := base
+	for {
+		if _, ok := s.occurrences[slug]; !ok {
+			break
+		}
+		s.occurrences[base]++
+		slug = base + "-" + strconv.Itoa(s.occurrences[base])
+	}
+	s.occurrences[slug] = 0
+	return slug
+}
@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestToLink(t *testing.T) {
+	cases := []struct{ heading, want string }{
+		{"Go", "go"},
+		{"C++", "c"},
+		{"C#", "c"},
+		{"F#", "f"},
+		{"Objective-C++", "objective-c"},
+		{"Visual Basic .NET", "visual-basic-net"},
+		{"Vim Script", "vim-script"},
+		{"Ren'Py", "renpy"},
+		{"Jupyter Notebook", "jupyter-notebook"},
+		{"snake_case", "snake_case"},
+		{"two  spaces", "two--spaces"},
+		{"2026-10", "2026-10"},
+		{"Ünïcödé Straße", "ünïcödé-straße"},
+		{"日本語 ドキュメント", "日本語-ドキュメント"},
+		{"rocket 🚀 launch", "rocket--launch"},
+	}
+	for _, tc := range cases {
+		if got := toLink(tc.heading); got != tc.want {
+			t.Errorf("toLink(%q) = %q, want %q", tc.heading, got, tc.want)
+		}
+	}
+}
+
+func TestSluggerAddsDuplicateSuffix(t *testing.T) {
+	s := newSlugger()
+	for _, tc := range []struct{ heading, want string }{
+		{"C", "c"},
+		{"C++", "c-1"},
+		{"C#", "c-2"},
+		{"c-1", "c-1-1"},
+		{"Go", "go"},
+		{"Go", "go-1"},
+	} {
+		if got := s.slug(tc.heading); got != tc.want {
+			t.Errorf("slug(%q) = %q, want %q", tc.heading, got, tc.want)
+		}
+	}
+}
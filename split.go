@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	_ "embed"
+)
+
+//go:embed templates/language.tmpl
+var languageTemplate []byte
+
+// splitDir is the directory holding the section pages written with --split.
+const splitDir = "languages"
+
+// splitPages returns the directory holding the section pages of the index
+// at index.
+func splitPages(index string) string {
+	return path.Join(path.Dir(index), splitDir)
+}
+
+// isStalePage reports whether name is a Markdown file directly in the pages
+// directory dir that is not among files, i.e. the page of a section that no
+// longer exists.
+func isStalePage(name, dir string, files []outputFile) bool {
+	if path.Dir(name) != dir || path.Ext(name) != ".md" {
+		return false
+	}
+	return !slices.ContainsFunc(files, func(file outputFile) bool { return file.Path == name })
+}
+
+// outputFile is a rendered file and its slash-separated path relative to the
+// output directory or repository root.
+type outputFile struct {
+	Path    string
+	Content []byte
+}
+
+// languagePage is the data passed to the template of a --split page.
+type languagePage struct {
+	Section  Section
+	UserName string
+	// Index is the relative link back to the index page.
+	Index string
+}
+
+// pageNames spells out the characters toLink drops, so "C++" and "C#" get
+// pages of their own instead of fighting over "c".
+var pageNames = strings.NewReplacer("+", "p", "#", "sharp")
+
+// renderSplit renders data as an index page at index listing the sections,
+// and one page per section in languages/ next to it linking back to it. The index is
+// rendered with the output template, which links sections to Section.File
+// instead of rendering them when Split is set.
+func renderSplit(data templateData, index string) ([]outputFile, error) {
+	page, err := parseTemplate(languageTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("template parse failed: %w", err)
+	}
+
+	s := newSlugger()
+	sections := make([]Section, len(data.Sections))
+	for i, section := range data.Sections {
+		section.File = path.Join(splitDir, s.slug(pageNames.Replace(section.Name))+".md")
+		sections[i] = section
+	}
+	data.Sections = sections
+	data.Split = true
+
+	var buf bytes.Buffer
+	if err := renderTemplate(&buf, data); err != nil {
+		return nil, err
+	}
+	files := []outputFile{{Path: index, Content: bytes.Clone(buf.Bytes())}}
+
+	// pages live one directory below the index
+	back := path.Join("..", path.Base(index))
+	for _, section := range sections {
+		buf.Reset()
+		if err := page.Execute(&buf, languagePage{Section: section, UserName: data.UserName, Index: back}); err != nil {
+			return nil, err
+		}
+		files = append(files, outputFile{Path: path.Join(path.Dir(index), section.File), Content: bytes.Clone(buf.Bytes())})
+	}
+	return files, nil
+}
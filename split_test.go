@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func splitFixture() templateData {
+	langRepoMap, repositories := groupByLanguage([]Repository{
+		{FullName: "a/cpp", URL: "https://github.com/a/cpp", Language: "C++"},
+		{FullName: "a/cs", URL: "https://github.com/a/cs", Language: "C#", Description: "a *bold* claim"},
+		{FullName: "a/go", URL: "https://github.com/a/go", Language: "Go"},
+	})
+	return templateData{
+		SortCmd:      true,
+		GroupBy:      "language",
+		LangRepoMap:  langRepoMap,
+		Sections:     newSections(langRepoMap, "alphabetical", nil),
+		UserName:     "juev",
+		Repositories: repositories,
+	}
+}
+
+func TestRenderSplit(t *testing.T) {
+	files, err := renderSplit(splitFixture(), "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	want := []string{"README.md", "languages/csharp.md", "languages/cpp.md", "languages/go.md"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+
+	index := string(files[0].Content)
+	for _, link := range []string{"- [C#](languages/csharp.md)", "- [C++](languages/cpp.md)", "- [Go](languages/go.md)"} {
+		if !strings.Contains(index, link) {
+			t.Errorf("index missing %q:\n%s", link, index)
+		}
+	}
+	if strings.Contains(index, "a/go") {
+		t.Errorf("index renders repositories:\n%s", index)
+	}
+
+	wantPage := `# C#
+
+[Back to contents](../README.md)
+
+- [a/cs](https://github.com/a/cs) – a \*bold\* claim
+`
+	if got := string(files[1].Content); got != wantPage {
+		t.Fatalf("page =\n%s\nwant\n%s", got, wantPage)
+	}
+}
+
+func TestRenderSplitKeepsSections(t *testing.T) {
+	data := splitFixture()
+	if _, err := renderSplit(data, "README.md"); err != nil {
+		t.Fatal(err)
+	}
+	for _, section := range data.Sections {
+		if section.File != "" {
+			t.Fatalf("section %q got file %q", section.Name, section.File)
+		}
+	}
+}
+
+func TestRenderSplitNestedIndex(t *testing.T) {
+	files, err := renderSplit(splitFixture(), "docs/stars.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := files[3].Path; got != "docs/languages/go.md" {
+		t.Errorf("page path = %q, want %q", got, "docs/languages/go.md")
+	}
+	if !strings.Contains(string(files[0].Content), "- [Go](languages/go.md)") {
+		t.Errorf("index does not link languages/go.md:\n%s", files[0].Content)
+	}
+	if !strings.Contains(string(files[3].Content), "[Back to contents](../stars.md)") {
+		t.Errorf("page does not link back to ../stars.md:\n%s", files[3].Content)
+	}
+}
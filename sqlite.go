@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchemaVersion is stored in PRAGMA user_version.
+const sqliteSchemaVersion = 1
+
+// sqliteSchema keeps one row per repository ever starred. Repositories that
+// disappear from the star list keep their row with unstarred_at set; starring
+// them again clears it. Timestamps are RFC 3339 text in UTC.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS languages (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS topics (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS repositories (
+	id            INTEGER PRIMARY KEY,
+	full_name     TEXT NOT NULL UNIQUE,
+	url           TEXT NOT NULL,
+	description   TEXT NOT NULL,
+	homepage      TEXT NOT NULL,
+	language_id   INTEGER REFERENCES languages(id),
+	owner         TEXT NOT NULL,
+	stars         INTEGER NOT NULL,
+	forks         INTEGER NOT NULL,
+	license       TEXT NOT NULL,
+	archived      INTEGER NOT NULL,
+	fork          INTEGER NOT NULL,
+	pushed_at     TEXT,
+	starred_at    TEXT,
+	first_seen_at TEXT NOT NULL,
+	last_seen_at  TEXT NOT NULL,
+	unstarred_at  TEXT
+);
+CREATE TABLE IF NOT EXISTS repository_topics (
+	repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+	topic_id      INTEGER NOT NULL REFERENCES topics(id),
+	PRIMARY KEY (repository_id, topic_id)
+);
+`
+
+const upsertRepository = `
+INSERT INTO repositories (
+	full_name, url, description, homepage, language_id, owner, stars, forks,
+	license, archived, fork, pushed_at, starred_at, first_seen_at, last_seen_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (full_name) DO UPDATE SET
+	url = excluded.url,
+	description = excluded.description,
+	homepage = excluded.homepage,
+	language_id = excluded.language_id,
+	owner = excluded.owner,
+	stars = excluded.stars,
+	forks = excluded.forks,
+	license = excluded.license,
+	archived = excluded.archived,
+	fork = excluded.fork,
+	pushed_at = excluded.pushed_at,
+	starred_at = excluded.starred_at,
+	last_seen_at = excluded.last_seen_at,
+	unstarred_at = NULL
+RETURNING id`
+
+// exportSQLite writes repositories into the SQLite database at path, creating
+// it when missing. Running it again updates the rows in place, and every
+// repository not in repositories that was still starred is marked as
+// unstarred at now. The whole export runs in one transaction.
+func exportSQLite(ctx context.Context, path string, repositories []Repository, now time.Time) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	defer db.Close()
+	// pragmas are per connection, so keep everything on one
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, "PRAGMA foreign_keys = ON"); err != nil {
+		return err
+	}
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > sqliteSchemaVersion {
+		return fmt.Errorf("%s has schema version %d, newer than supported %d", path, version, sqliteSchemaVersion)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// rolling back after Commit is a no-op
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, sqliteSchema); err != nil {
+		return fmt.Errorf("cannot create schema: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+		return err
+	}
+
+	seenAt := sqliteTime(now)
+	for _, repo := range repositories {
+		if err := upsertSQLiteRepository(ctx, tx, repo, seenAt); err != nil {
+			return fmt.Errorf("cannot export %s: %w", repo.FullName, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE repositories SET unstarred_at = ? WHERE last_seen_at <> ? AND unstarred_at IS NULL",
+		seenAt, seenAt); err != nil {
+		return fmt.Errorf("cannot mark unstarred repositories: %w", err)
+	}
+	return tx.Commit()
+}
+
+func upsertSQLiteRepository(ctx context.Context, tx *sql.Tx, repo Repository, seenAt any) error {
+	var languageID any
+	if repo.Language != "" {
+		id, err := sqliteNameID(ctx, tx, "languages", languageSection(repo))
+		if err != nil {
+			return err
+		}
+		languageID = id
+	}
+
+	var id int64
+	err := tx.QueryRowContext(ctx, upsertRepository,
+		repo.FullName, repo.URL, repo.Description, repo.Homepage, languageID, repo.Owner.Login,
+		repo.Stars, repo.Forks, repo.License, repo.Archived, repo.Fork,
+		sqliteTime(repo.PushedAt), sqliteTime(repo.StarredAt), seenAt, seenAt,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM repository_topics WHERE repository_id = ?", id); err != nil {
+		return err
+	}
+	for _, topic := range repo.Topics {
+		topicID, err := sqliteNameID(ctx, tx, "topics", topic)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO repository_topics (repository_id, topic_id) VALUES (?, ?)",
+			id, topicID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteNameID returns the id of name in the languages or topics table,
+// inserting it when missing.
+func sqliteNameID(ctx context.Context, tx *sql.Tx, table, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx,
+		"INSERT INTO "+table+" (name) VALUES (?) ON CONFLICT (name) DO UPDATE SET name = excluded.name RETURNING id",
+		name).Scan(&id)
+	return id, err
+}
+
+// sqliteTime formats t for storage; the zero time is stored as NULL.
+func sqliteTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExportSQLiteUpsertsAndTracksUnstarred(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stars.db")
+	ctx := context.Background()
+	first := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	third := second.Add(24 * time.Hour)
+
+	kept := Repository{
+		FullName: "a/kept", URL: "https://github.com/a/kept", Language: "VimL", Stars: 1,
+		Topics: []string{"vim", "editor"}, StarredAt: first.Add(-time.Hour), Owner: Owner{Login: "a"},
+	}
+	dropped := Repository{FullName: "a/dropped", URL: "https://github.com/a/dropped"}
+
+	if err := exportSQLite(ctx, path, []Repository{kept, dropped}, first); err != nil {
+		t.Fatal(err)
+	}
+	kept.Stars = 2
+	kept.Topics = []string{"vim"}
+	if err := exportSQLite(ctx, path, []Repository{kept}, second); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	var (
+		stars               int
+		language            string
+		firstSeen, lastSeen string
+		starredAt           sql.NullString
+		keptUnstarred       sql.NullString
+		droppedUnstarred    sql.NullString
+	)
+	err = db.QueryRow(`
+		SELECT r.stars, l.name, r.first_seen_at, r.last_seen_at, r.starred_at, r.unstarred_at
+		FROM repositories r JOIN languages l ON l.id = r.language_id
+		WHERE r.full_name = 'a/kept'`).Scan(&stars, &language, &firstSeen, &lastSeen, &starredAt, &keptUnstarred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stars != 2 || language != "Vim Script" {
+		t.Errorf("stars/language = %d/%q, want 2/Vim Script", stars, language)
+	}
+	if firstSeen != "2026-10-01T00:00:00Z" || lastSeen != "2026-10-02T00:00:00Z" || keptUnstarred.Valid {
+		t.Errorf("first/last seen = %s/%s, unstarred = %v", firstSeen, lastSeen, keptUnstarred)
+	}
+	if starredAt.String != "2026-09-30T23:00:00Z" {
+		t.Errorf("starred_at = %v", starredAt)
+	}
+
+	rows, err := db.Query(`
+		SELECT t.name FROM repository_topics rt
+		JOIN topics t ON t.id = rt.topic_id
+		JOIN repositories r ON r.id = rt.repository_id
+		WHERE r.full_name = 'a/kept' ORDER BY t.name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var topics []string
+	for rows.Next() {
+		var topic string
+		if err := rows.Scan(&topic); err != nil {
+			t.Fatal(err)
+		}
+		topics = append(topics, topic)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(topics, []string{"vim"}) {
+		t.Errorf("topics = %v, want [vim]", topics)
+	}
+
+	if err := db.QueryRow(`SELECT unstarred_at FROM repositories WHERE full_name = 'a/dropped'`).Scan(&droppedUnstarred); err != nil {
+		t.Fatal(err)
+	}
+	if droppedUnstarred.String != "2026-10-02T00:00:00Z" {
+		t.Errorf("dropped unstarred_at = %v, want second run time", droppedUnstarred)
+	}
+
+	// starring the repository again clears unstarred_at
+	if err := exportSQLite(ctx, path, []Repository{kept, dropped}, third); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT unstarred_at FROM repositories WHERE full_name = 'a/dropped'`).Scan(&droppedUnstarred); err != nil {
+		t.Fatal(err)
+	}
+	if droppedUnstarred.Valid {
+		t.Errorf("restarred unstarred_at = %v, want NULL", droppedUnstarred)
+	}
+}
@@ -1,10 +1,37 @@
 package main
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// assertGolden compares got with testdata/name, rewriting the file when the
+// tests run with -update.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read golden file (run go test -update): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s:\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
 func renderEmbeddedTemplate(t *testing.T, data templateData) string {
 	t.Helper()
 	temp, err := parseTemplate(content)
@@ -19,18 +46,20 @@ func renderEmbeddedTemplate(t *testing.T, data templateData) string {
 }
 
 func TestRenderTemplateSortMode(t *testing.T) {
-	out := renderEmbeddedTemplate(t, templateData{
-		SortCmd:  true,
-		UserName: "juev",
-		LangRepoMap: map[string][]Repository{
-			"Go": {
-				{FullName: "a/b", URL: "https://github.com/a/b", Language: "Go", Description: "with description"},
-				{FullName: "a/c", URL: "https://github.com/a/c", Language: "Go"},
-			},
-			"Others": {
-				{FullName: "x/y", URL: "https://github.com/x/y"},
-			},
+	langRepoMap := map[string][]Repository{
+		"Go": {
+			{FullName: "a/b", URL: "https://github.com/a/b", Language: "Go", Description: "with description"},
+			{FullName: "a/c", URL: "https://github.com/a/c", Language: "Go"},
+		},
+		"Others": {
+			{FullName: "x/y", URL: "https://github.com/x/y"},
 		},
+	}
+	out := renderEmbeddedTemplate(t, templateData{
+		SortCmd:     true,
+		UserName:    "juev",
+		LangRepoMap: langRepoMap,
+		Sections:    newSections(langRepoMap, "alphabetical", nil),
 	})
 
 	for _, want := range []string{
@@ -74,3 +103,168 @@ func TestRenderTemplateFlatMode(t *testing.T) {
 		t.Error("flat mode must not render the contents section")
 	}
 }
+
+func TestRenderTemplateTopicMode(t *testing.T) {
+	topicRepoMap := map[string][]Repository{
+		"kubernetes":    {{FullName: "a/b", URL: "https://github.com/a/b", Description: "operator"}},
+		"Uncategorized": {{FullName: "x/y", URL: "https://github.com/x/y"}},
+	}
+	out := renderEmbeddedTemplate(t, templateData{
+		SortCmd:  true,
+		GroupBy:  "topic",
+		UserName: "juev",
+		LangRepoMap: map[string][]Repository{
+			"Go": {{FullName: "a/b", URL: "https://github.com/a/b", Language: "Go"}},
+		},
+		TopicRepoMap: topicRepoMap,
+		Sections:     newSections(topicRepoMap, "alphabetical", nil),
+	})
+
+	for _, want := range []string{
+		"## Contents",
+		"- [kubernetes](#kubernetes)",
+		"- [Uncategorized](#uncategorized)",
+		"## kubernetes",
+		"- [a/b](https://github.com/a/b) – operator",
+		"- [x/y](https://github.com/x/y)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "## Go") {
+		t.Error("topic mode must not render language sections")
+	}
+	if strings.Index(out, "## Uncategorized") < strings.Index(out, "## kubernetes") {
+		t.Errorf("Uncategorized must be the last section:\n%s", out)
+	}
+}
+
+func TestRenderTemplateOwnerMode(t *testing.T) {
+	owner := Owner{Login: "hashicorp", URL: "https://github.com/hashicorp", AvatarURL: "https://avatars.githubusercontent.com/u/761456?v=4"}
+	ownerRepoMap := map[string][]Repository{
+		"hashicorp": {{FullName: "hashicorp/vault", URL: "https://github.com/hashicorp/vault", Owner: owner}},
+	}
+	out := renderEmbeddedTemplate(t, templateData{
+		SortCmd:      true,
+		GroupBy:      "owner",
+		UserName:     "juev",
+		OwnerRepoMap: ownerRepoMap,
+		Sections:     newSections(ownerRepoMap, "alphabetical", nil),
+	})
+
+	for _, want := range []string{
+		"- [hashicorp](#hashicorp)",
+		"## hashicorp",
+		`<a href="https://github.com/hashicorp"><img src="https://avatars.githubusercontent.com/u/761456?v=4" alt="hashicorp" width="48" height="48"></a>`,
+		"[github.com/hashicorp](https://github.com/hashicorp)",
+		"- [hashicorp/vault](https://github.com/hashicorp/vault)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderTemplateStarredDateMode(t *testing.T) {
+	dateSections := []Section{
+		{Name: "2026", Anchor: "2026", Repos: []Repository{{FullName: "a/new", URL: "https://github.com/a/new"}}},
+		{Name: "2025", Anchor: "2025", Repos: []Repository{{FullName: "a/old", URL: "https://github.com/a/old"}}},
+	}
+	out := renderEmbeddedTemplate(t, templateData{
+		SortCmd:      true,
+		GroupBy:      "starred-date",
+		UserName:     "juev",
+		DateSections: dateSections,
+		Sections:     dateSections,
+	})
+
+	toc2026 := strings.Index(out, "- [2026](#2026)")
+	toc2025 := strings.Index(out, "- [2025](#2025)")
+	if toc2026 < 0 || toc2025 < 0 || toc2026 > toc2025 {
+		t.Errorf("contents must list 2026 before 2025:\n%s", out)
+	}
+	if strings.Index(out, "## 2026") > strings.Index(out, "## 2025") {
+		t.Errorf("sections must keep the given order:\n%s", out)
+	}
+	if !strings.Contains(out, "- [a/new](https://github.com/a/new)\n") {
+		t.Errorf("output missing repository line:\n%s", out)
+	}
+}
+
+func TestRenderTemplateUsesGitHubHeadingAnchors(t *testing.T) {
+	langRepoMap := map[string][]Repository{
+		"C++":               {{FullName: "a/cpp", URL: "https://github.com/a/cpp"}},
+		"C#":                {{FullName: "a/cs", URL: "https://github.com/a/cs"}},
+		"Visual Basic .NET": {{FullName: "a/vb", URL: "https://github.com/a/vb"}},
+	}
+	out := renderEmbeddedTemplate(t, templateData{
+		SortCmd:     true,
+		UserName:    "juev",
+		LangRepoMap: langRepoMap,
+		Sections:    newSections(langRepoMap, "alphabetical", nil),
+	})
+
+	for _, want := range []string{
+		"- [C#](#c)",
+		"- [C++](#c-1)",
+		"- [Visual Basic .NET](#visual-basic-net)",
+		"\n## C++\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<div id=") {
+		t.Errorf("plain Markdown headings must not need raw anchors:\n%s", out)
+	}
+}
+
+func TestRenderTemplateEscapesDescriptions(t *testing.T) {
+	nasty := []Repository{
+		{FullName: "a/pipes", URL: "https://github.com/a/pipes", Description: "a | b | c table breaker"},
+		{FullName: "a/links", URL: "https://github.com/a/links", Description: "[click](https://evil.example) ![img](x.png)"},
+		{FullName: "a/script", URL: "https://github.com/a/script", Description: "<script>alert('x')</script> & <b>bold</b>"},
+		{FullName: "a/code", URL: "https://github.com/a/code", Description: "use `rm -rf /` *carefully* with __init__ and ~~strike~~"},
+		{FullName: "a/newlines", URL: "https://github.com/a/newlines", Description: "first line\n## injected heading\r\n- injected item"},
+		{FullName: "a/heading", URL: "https://github.com/a/heading", Description: "# not a heading"},
+		{FullName: "a/backslash", URL: "https://github.com/a/backslash", Description: `C:\path\*`},
+	}
+	langRepoMap := map[string][]Repository{"Go": nasty}
+	out := renderEmbeddedTemplate(t, templateData{
+		SortCmd:     true,
+		UserName:    "juev",
+		LangRepoMap: langRepoMap,
+		Sections:    newSections(langRepoMap, "alphabetical", nil),
+	})
+	assertGolden(t, "escape_descriptions.golden", out)
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	langRepoMap := map[string][]Repository{
+		"C++": {{FullName: "a/cpp", URL: "https://github.com/a/cpp", Description: "fast *and* /lean/ _tidy_ =code= ~verb~ +gone+ [small](x) {attr} <b>"}},
+		"C#":  {{FullName: "a/cs", URL: "https://github.com/a/cs", Description: "# line\nbreak ]]"}},
+		"Others": {
+			{FullName: "x/y", URL: "https://github.com/x/y"},
+		},
+	}
+	for name, builtin := range builtinTemplates {
+		t.Run(name, func(t *testing.T) {
+			temp, err := parseTemplate(builtin.content)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			var sb strings.Builder
+			err = temp.Execute(&sb, templateData{
+				SortCmd:     true,
+				UserName:    "juev",
+				LangRepoMap: langRepoMap,
+				Sections:    newSections(langRepoMap, "alphabetical", nil),
+			})
+			if err != nil {
+				t.Fatalf("unexpected execute error: %v", err)
+			}
+			assertGolden(t, "builtin_"+name+".golden", sb.String())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+(function () {
+  "use strict";
+
+  var table = document.getElementById("stars");
+  var tbody = table.tBodies[0];
+  var rows = Array.prototype.slice.call(tbody.rows);
+  var search = document.getElementById("search");
+  var empty = document.getElementById("empty");
+  var language = "";
+
+  function filter() {
+    var query = search.value.trim().toLowerCase();
+    var visible = 0;
+    rows.forEach(function (row) {
+      var text = (row.cells[0].textContent + " " + row.cells[1].textContent).toLowerCase();
+      var show = (language === "" || row.dataset.language === language) &&
+        (query === "" || text.indexOf(query) !== -1);
+      row.hidden = !show;
+      if (show) {
+        visible++;
+      }
+    });
+    empty.hidden = visible !== 0;
+  }
+
+  function value(row, index, type) {
+    var cell = row.cells[index];
+    if (type === "number") {
+      return Number(cell.dataset.value);
+    }
+    return cell.textContent.trim().toLowerCase();
+  }
+
+  Array.prototype.forEach.call(table.tHead.rows[0].cells, function (th, index) {
+    th.addEventListener("click", function () {
+      var descending = th.getAttribute("aria-sort") === "ascending";
+      Array.prototype.forEach.call(th.parentNode.cells, function (other) {
+        other.removeAttribute("aria-sort");
+      });
+      th.setAttribute("aria-sort", descending ? "descending" : "ascending");
+      var type = th.dataset.type;
+      rows.sort(function (a, b) {
+        var x = value(a, index, type);
+        var y = value(b, index, type);
+        var order = x < y ? -1 : x > y ? 1 : 0;
+        return descending ? -order : order;
+      });
+      rows.forEach(function (row) {
+        tbody.appendChild(row);
+      });
+    });
+  });
+
+  document.getElementById("languages").addEventListener("click", function (event) {
+    var link = event.target.closest("a");
+    if (!link) {
+      return;
+    }
+    event.preventDefault();
+    language = link.dataset.language;
+    Array.prototype.forEach.call(this.querySelectorAll("a"), function (other) {
+      other.classList.toggle("active", other === link);
+    });
+    filter();
+  });
+
+  search.addEventListener("input", filter);
+})();